@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// achievabilityColumns are the columns of the target achievability review
+var achievabilityColumns = []string{
+	"name",
+	"slo_id",
+	"target",
+	"sli_90d",
+	"error_budget_consumed_90d",
+	"days_met_pct",
+	"finding",
+	"suggested_target_low",
+	"suggested_target_high",
+	"error",
+}
+
+// achievability findings of a target against the 90 day sli
+const (
+	FindingChronicBreach = "CHRONIC_BREACH"
+	FindingTriviallyMet  = "TRIVIALLY_MET"
+	FindingAchievable    = "ACHIEVABLE"
+)
+
+// runAchievability compares each slo's target to its last 90 days sli, flagging targets that are not met
+// (chronic breaches) or met without using the error budget (trivially met), with the range of targets whose
+// error budget the last 90 days would have consumed 50 to 100% of
+func runAchievability(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("achievability", flag.ContinueOnError)
+	output := fs.String("o", "slo_achievability.csv", "path of the csv review")
+	trivialBudget := fs.Float64("trivial-budget", 10, "error budget consumed percentage over 90 days below which a target is trivially met")
+	parseFlags(fs, args)
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		fatalf("Unable to create file: %s, err: %s", *output, err)
+	}
+	defer file.Close()
+	writer, err := newCSVWriter(file)
+	if err != nil {
+		fatalf("Unable to write to file: %s, err: %s", *output, err)
+	}
+	defer writer.Flush()
+	if err := writer.Write(achievabilityColumns); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", *output, err)
+	}
+
+	apiClient := newAPIClient()
+	to := runClock.Now().UTC()
+	from := to.Add(-NinetyDays)
+	findings := map[string]int{}
+	for counter, slo := range slos {
+		if len(slo.Thresholds) == 0 {
+			log.Printf("Skipping s: %s, err: slo has no thresholds", slo.GetId())
+			continue
+		}
+		// the review is of the first configured target
+		threshold := slo.Thresholds[0]
+		log.Printf("(%d of %d) Getting 90 day SLO history s: %s", counter+1, len(slos), slo.GetId())
+		history, err := getSLOHistory(ctx, apiClient, slo, threshold, from, to)
+		var data []string
+		if err != nil {
+			log.Printf("Unable to get slo history s: %s, err: %s", slo.GetId(), err)
+			data = []string{slo.GetName(), slo.GetId(), fmt.Sprintf("%f", threshold.GetTarget()), "", "", "", "", "", "", fmt.Sprintf("api error: %s", err)}
+		} else {
+			review := reviewTarget(threshold.GetTarget(), *history, from, *trivialBudget)
+			findings[review.finding]++
+			data = append([]string{slo.GetName(), slo.GetId(), fmt.Sprintf("%f", threshold.GetTarget())}, review.values()...)
+		}
+		if err := writer.Write(data); err != nil {
+			fatalf("Unable to write to file: %s, err: %s", *output, err)
+		}
+		if err := sleepContext(ctx, options.sleep); err != nil {
+			writer.Flush()
+			fatalf("Achievability review stopped after %d of %d SLOs, err: %s", counter+1, len(slos), err)
+		}
+	}
+	log.Printf("Done - %d chronic breaches, %d trivially met and %d achievable targets of %d SLOs written to: %s",
+		findings[FindingChronicBreach], findings[FindingTriviallyMet], findings[FindingAchievable], len(slos), *output)
+}
+
+// targetReview is the achievability of a target over 90 days
+type targetReview struct {
+	sli, budgetConsumed, daysMet *float64
+	finding                      string
+	low, high                    *float64
+}
+
+// reviewTarget classifies the target against the 90 day sli: a chronic breach when the sli is below the target
+// and the target was met on less than half the days (known for metric slos), breached when it was met on most days,
+// trivially met when less than trivialBudget percent of the error budget was consumed, otherwise achievable
+func reviewTarget(target float64, history datadog.SLOHistoryResponse, from time.Time, trivialBudget float64) targetReview {
+	var review targetReview
+	sli, ok := history.Data.Overall.GetSliValueOk()
+	if !ok {
+		review.finding = StatusNoData
+		return review
+	}
+	review.sli = sli
+	consumed := 100 - errorBudgetRemaining(*sli, target)
+	review.budgetConsumed = &consumed
+
+	if series, ok := history.Data.GetSeriesOk(); ok {
+		good, total := dailyEvents(series, from, int(NinetyDays/OneDay))
+		days, met := 0, 0
+		for day := range total {
+			if total[day] == 0 {
+				continue
+			}
+			days++
+			if good[day]/total[day]*100 >= target {
+				met++
+			}
+		}
+		if days > 0 {
+			pct := float64(met) / float64(days) * 100
+			review.daysMet = &pct
+		}
+	}
+
+	switch {
+	case *sli < target && (review.daysMet == nil || *review.daysMet < 50):
+		review.finding = FindingChronicBreach
+	case *sli < target:
+		review.finding = StatusBreached
+	case consumed < trivialBudget:
+		review.finding = FindingTriviallyMet
+	default:
+		review.finding = FindingAchievable
+	}
+	// targets whose error budget (100 - target) the errors (100 - sli) consume 50 to 100% of
+	low, high := math.Max(0, 100-2*(100-*sli)), *sli
+	review.low, review.high = &low, &high
+	return review
+}
+
+// values returns the review column values
+func (r targetReview) values() []string {
+	return []string{
+		formatOptionalFloat(r.sli),
+		formatOptionalFloat(r.budgetConsumed),
+		formatOptionalFloat(r.daysMet),
+		r.finding,
+		formatOptionalFloat(r.low),
+		formatOptionalFloat(r.high),
+		"",
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// managedByTag is added to monitors provisioned by this script, so they can be found and updated
+const managedByTag = "managed-by:slo-report"
+
+// alertTemplate describes a burn rate or error budget monitor provisioned for each slo
+type alertTemplate struct {
+	// ID identifies monitors provisioned from the template e.g fast-burn, tagged slo_alert:<id>
+	ID string `json:"id"`
+	// Kind is burn_rate or error_budget
+	Kind string `json:"kind"`
+	// Timeframe is the slo timeframe evaluated, defaults to the slo's first threshold timeframe
+	Timeframe   string `json:"timeframe"`
+	LongWindow  string `json:"long_window"`
+	ShortWindow string `json:"short_window"`
+	// Critical (and optional Warning) are burn rates, or percentages of error budget consumed
+	Critical float64  `json:"critical"`
+	Warning  *float64 `json:"warning"`
+	// Name and Message are text/templates over the slo e.g {{.Name}}, {{.Id}}, and {{.Timeframe}}
+	Name    string   `json:"name"`
+	Message string   `json:"message"`
+	Handles []string `json:"handles"`
+}
+
+// defaultAlertTemplates are multi-window burn rate alerts (fast and slow burn) used without -template
+var defaultAlertTemplates = []alertTemplate{
+	{
+		ID:          "fast-burn",
+		Kind:        "burn_rate",
+		LongWindow:  "1h",
+		ShortWindow: "5m",
+		Critical:    14.4,
+		Name:        "[SLO] {{.Name}} fast burn",
+		Message:     "SLO {{.Name}} is burning its {{.Timeframe}} error budget 14.4x faster than sustainable.",
+	},
+	{
+		ID:          "slow-burn",
+		Kind:        "burn_rate",
+		LongWindow:  "6h",
+		ShortWindow: "30m",
+		Critical:    6,
+		Name:        "[SLO] {{.Name}} slow burn",
+		Message:     "SLO {{.Name}} is burning its {{.Timeframe}} error budget 6x faster than sustainable.",
+	},
+}
+
+// runProvisionAlerts creates or updates monitors from the alert templates for each slo matching the tag query
+func runProvisionAlerts(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("provision-alerts", flag.ContinueOnError)
+	templatePath := fs.String("template", "", "path of a json list of alert templates (default fast and slow burn rate alerts)")
+	handles := stringList{}
+	fs.Var(&handles, "handles", "comma separated notification handles added to every message e.g @slack-sre,@pagerduty-sre")
+	dryRun := fs.Bool("dry-run", false, "log the monitors that would be created or updated without changing them")
+	parseFlags(fs, args)
+	if !*dryRun {
+		requireWritable("provision-alerts")
+	}
+
+	templates := defaultAlertTemplates
+	if *templatePath != "" {
+		content, err := ioutil.ReadFile(*templatePath)
+		if err != nil {
+			fatalf("Unable to read template: %s, err: %s", *templatePath, err)
+		}
+		templates = nil
+		if err := json.Unmarshal(content, &templates); err != nil {
+			fatalf("Unable to parse template: %s, err: %s", *templatePath, err)
+		}
+	}
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+
+	apiClient := newAPIClient()
+	existing, err := getManagedMonitors(ctx, apiClient)
+	if err != nil {
+		fatalf("Error when calling `MonitorsApi.ListMonitors`: %v\n", err)
+	}
+
+	created, updated, failed := 0, 0, 0
+	for counter, slo := range slos {
+		for _, tmpl := range templates {
+			monitor, err := newAlertMonitor(slo, tmpl, handles)
+			if err != nil {
+				log.Printf("(%d of %d) Unable to render alert s: %s, t: %s, err: %s", counter+1, len(slos), slo.GetId(), tmpl.ID, err)
+				failed++
+				continue
+			}
+
+			current, found := existing[alertKey(slo.GetId(), tmpl.ID)]
+			action := "Creating"
+			if found {
+				action = "Updating"
+			}
+			log.Printf("(%d of %d) %s monitor s: %s, t: %s, q: %s", counter+1, len(slos), action, slo.GetId(), tmpl.ID, monitor.Query)
+			if *dryRun {
+				continue
+			}
+
+			if found {
+				_, _, err = apiClient.MonitorsApi.UpdateMonitor(ctx, current.GetId(), datadog.MonitorUpdateRequest{
+					Name:    monitor.Name,
+					Query:   &monitor.Query,
+					Message: monitor.Message,
+					Tags:    monitor.Tags,
+					Options: monitor.Options,
+				})
+				updated++
+			} else {
+				_, _, err = apiClient.MonitorsApi.CreateMonitor(ctx, *monitor)
+				created++
+			}
+			if err != nil {
+				log.Printf("Unable to provision monitor s: %s, t: %s, err: %s", slo.GetId(), tmpl.ID, err)
+				failed++
+			}
+			if err := sleepContext(ctx, options.sleep); err != nil {
+				fatalf("Alert provisioning stopped, %d monitors created, %d updated, %d failed, err: %s", created, updated, failed, err)
+			}
+		}
+	}
+	log.Printf("Done - %d monitors created, %d updated, %d failed", created, updated, failed)
+}
+
+// newAlertMonitor renders the template for the slo
+func newAlertMonitor(slo datadog.ServiceLevelObjective, tmpl alertTemplate, handles []string) (*datadog.Monitor, error) {
+	timeframe := tmpl.Timeframe
+	if timeframe == "" && len(slo.Thresholds) > 0 {
+		timeframe = string(slo.Thresholds[0].Timeframe)
+	}
+
+	var query string
+	switch tmpl.Kind {
+	case "burn_rate":
+		query = fmt.Sprintf(`burn_rate("%s").over("%s").long_window("%s").short_window("%s") > %g`,
+			slo.GetId(), timeframe, tmpl.LongWindow, tmpl.ShortWindow, tmpl.Critical)
+	case "error_budget":
+		query = fmt.Sprintf(`error_budget("%s").over("%s") > %g`, slo.GetId(), timeframe, tmpl.Critical)
+	default:
+		return nil, fmt.Errorf("unsupported alert kind: %s, expected burn_rate or error_budget", tmpl.Kind)
+	}
+
+	data := map[string]string{"Name": slo.GetName(), "Id": slo.GetId(), "Timeframe": timeframe}
+	name, err := renderTemplate(tmpl.Name, data)
+	if err != nil {
+		return nil, err
+	}
+	message, err := renderTemplate(tmpl.Message, data)
+	if err != nil {
+		return nil, err
+	}
+	if allHandles := append(append([]string{}, tmpl.Handles...), handles...); len(allHandles) > 0 {
+		message += "\n\n" + strings.Join(allHandles, " ")
+	}
+
+	thresholds := datadog.NewMonitorThresholds()
+	thresholds.SetCritical(tmpl.Critical)
+	if tmpl.Warning != nil {
+		thresholds.SetWarning(*tmpl.Warning)
+	}
+	monitorOptions := datadog.NewMonitorOptions()
+	monitorOptions.SetThresholds(*thresholds)
+
+	monitor := datadog.NewMonitor(query, datadog.MONITORTYPE_SLO_ALERT)
+	monitor.SetName(name)
+	monitor.SetMessage(message)
+	monitor.SetTags([]string{managedByTag, "slo_id:" + slo.GetId(), "slo_alert:" + tmpl.ID})
+	monitor.SetOptions(*monitorOptions)
+	return monitor, nil
+}
+
+// renderTemplate executes the text template with data
+func renderTemplate(text string, data interface{}) (string, error) {
+	tmpl, err := template.New("alert").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// alertKey identifies a provisioned monitor by slo and template
+func alertKey(sloID, templateID string) string {
+	return sloID + "/" + templateID
+}
+
+// getManagedMonitors returns the monitors provisioned by this script keyed by alertKey
+func getManagedMonitors(ctx context.Context, apiClient *datadog.APIClient) (map[string]datadog.Monitor, error) {
+	all, err := listAllMonitors(ctx, apiClient, managedByTag)
+	if err != nil {
+		return nil, err
+	}
+	monitors := map[string]datadog.Monitor{}
+	for _, monitor := range all {
+		var sloID, templateID string
+		for _, tag := range monitor.GetTags() {
+			if strings.HasPrefix(tag, "slo_id:") {
+				sloID = strings.TrimPrefix(tag, "slo_id:")
+			}
+			if strings.HasPrefix(tag, "slo_alert:") {
+				templateID = strings.TrimPrefix(tag, "slo_alert:")
+			}
+		}
+		if sloID != "" && templateID != "" {
+			monitors[alertKey(sloID, templateID)] = monitor
+		}
+	}
+	return monitors, nil
+}
+
+// listAllMonitors returns all monitors, filtered by the comma separated monitor tags if set
+func listAllMonitors(ctx context.Context, apiClient *datadog.APIClient, monitorTags string) ([]datadog.Monitor, error) {
+	var monitors []datadog.Monitor
+	pageSize := int32(1000)
+	for page := int64(0); ; page++ {
+		p := page
+		optionalParams := datadog.ListMonitorsOptionalParameters{
+			Page:     &p,
+			PageSize: &pageSize,
+		}
+		if monitorTags != "" {
+			optionalParams.MonitorTags = &monitorTags
+		}
+		resp, _, err := apiClient.MonitorsApi.ListMonitors(ctx, optionalParams)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, resp...)
+		if len(resp) < int(pageSize) {
+			return monitors, nil
+		}
+		if err := sleepContext(ctx, options.sleep); err != nil {
+			return nil, err
+		}
+	}
+}
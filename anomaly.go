@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// anomalyColumns compare the error budget consumed to the previous reports, with -baseline-reports
+var anomalyColumns = []string{"budget_consumed_mean", "budget_consumed_stddev", "anomaly"}
+
+// minBaselineRuns is the number of previous values needed to flag anomalies
+const minBaselineRuns = 3
+
+// baselineKey identifies an slo timeframe across reports
+type baselineKey struct {
+	sloID, timeframe string
+}
+
+// baseline is the historical error budget consumption of an slo timeframe
+type baseline struct {
+	runs         int
+	mean, stddev float64
+}
+
+// baselines are the error budget consumption baselines loaded from -baseline-reports
+var baselines map[baselineKey]*baseline
+
+// loadBaselines returns the mean and standard deviation of the error budget consumed of each slo timeframe
+// in the previous report csv files matching the glob pattern, group and period rows are left out
+func loadBaselines(pattern string) (map[baselineKey]*baseline, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	values := map[baselineKey][]float64{}
+	for _, path := range paths {
+		if err := readBaselineReport(path, values); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	result := map[baselineKey]*baseline{}
+	for key, consumed := range values {
+		var sum float64
+		for _, v := range consumed {
+			sum += v
+		}
+		mean := sum / float64(len(consumed))
+		var squares float64
+		for _, v := range consumed {
+			squares += (v - mean) * (v - mean)
+		}
+		result[key] = &baseline{runs: len(consumed), mean: mean, stddev: math.Sqrt(squares / float64(len(consumed)))}
+	}
+	return result, nil
+}
+
+// readBaselineReport adds the error budget consumed of each slo timeframe row of the report to values
+func readBaselineReport(path string, values map[baselineKey][]float64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	header := records[0]
+	header[0] = strings.TrimPrefix(header[0], utf8BOM)
+	for _, record := range records[1:] {
+		lookup := recordLookup(header, record)
+		group, _ := lookup("group")
+		period, _ := lookup("period")
+		if group != "" || period != "" {
+			continue
+		}
+		id, _ := lookup("slo_id")
+		timeframe, _ := lookup("timeframe")
+		consumed, _ := lookup("error_budget_consumed")
+		v, err := strconv.ParseFloat(strings.Replace(consumed, ",", ".", 1), 64)
+		if err != nil {
+			continue
+		}
+		key := baselineKey{id, timeframe}
+		values[key] = append(values[key], v)
+	}
+	return nil
+}
+
+// anomalyValues returns the row's baseline mean and standard deviation and whether its error budget consumed
+// is more than -anomaly-stddev standard deviations above the mean
+func (r reportRow) anomalyValues() []string {
+	b := baselines[baselineKey{r.slo.GetId(), r.timeframeLabel()}]
+	if b == nil || b.runs < minBaselineRuns || r.group != "" || r.period != "" {
+		return make([]string, len(anomalyColumns))
+	}
+	anomaly := ""
+	if r.errorBudgetConsumed != nil {
+		anomaly = fmt.Sprintf("%t", *r.errorBudgetConsumed-b.mean > options.anomalyStddev*b.stddev)
+	}
+	return []string{formatOptionalFloat(&b.mean), formatOptionalFloat(&b.stddev), anomaly}
+}
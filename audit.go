@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// sloAlertQuery matches the alert kind and slo id of slo alert monitor queries
+// e.g burn_rate("slo_id").over("30d")... or error_budget("slo_id").over("30d")...
+var sloAlertQuery = regexp.MustCompile(`(burn_rate|error_budget)\("([^"]+)"\)`)
+
+// runAuditAlerts writes each slo matching the tag query with the number of burn rate and
+// error budget monitors alerting on it to stdout, flagging slos without any
+func runAuditAlerts(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("audit-alerts", flag.ContinueOnError)
+	missingOnly := fs.Bool("missing-only", false, "only list slos without burn rate or error budget alerts")
+	parseFlags(fs, args)
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+
+	apiClient := newAPIClient()
+	monitors, err := listAllMonitors(ctx, apiClient, "")
+	if err != nil {
+		fatalf("Error when calling `MonitorsApi.ListMonitors`: %v\n", err)
+	}
+	burnRate, errorBudget := countSLOAlerts(monitors)
+
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	cols := []string{"name", "slo_id", "burn_rate_alerts", "error_budget_alerts", "covered"}
+	if err := writer.Write(cols); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+
+	uncovered := 0
+	for _, slo := range slos {
+		covered := burnRate[slo.GetId()]+errorBudget[slo.GetId()] > 0
+		if !covered {
+			uncovered++
+		}
+		if covered && *missingOnly {
+			continue
+		}
+		data := []string{
+			slo.GetName(),
+			slo.GetId(),
+			fmt.Sprintf("%d", burnRate[slo.GetId()]),
+			fmt.Sprintf("%d", errorBudget[slo.GetId()]),
+			fmt.Sprintf("%t", covered),
+		}
+		if err := writer.Write(data); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+	}
+	log.Printf("Done - %d of %d SLOs have no burn rate or error budget alert", uncovered, len(slos))
+}
+
+// countSLOAlerts returns the number of burn rate and error budget monitors per slo id
+func countSLOAlerts(monitors []datadog.Monitor) (map[string]int, map[string]int) {
+	burnRate, errorBudget := map[string]int{}, map[string]int{}
+	for _, monitor := range monitors {
+		if monitor.Type != datadog.MONITORTYPE_SLO_ALERT {
+			continue
+		}
+		for _, match := range sloAlertQuery.FindAllStringSubmatch(monitor.Query, -1) {
+			if match[1] == "burn_rate" {
+				burnRate[match[2]]++
+			} else {
+				errorBudget[match[2]]++
+			}
+		}
+	}
+	return burnRate, errorBudget
+}
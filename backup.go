@@ -0,0 +1,272 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// runBackup writes the definitions of the slos matching the tag query to a directory, or a .tar.gz archive
+func runBackup(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := fs.String("o", "slo_backup", "directory the definitions are written to, or a .tar.gz archive path")
+	parseFlags(fs, args)
+
+	slos, err := getSLODefinitions(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	if err := writeBackup(*output, slos); err != nil {
+		fatalf("Unable to write backup: %s, err: %s", *output, err)
+	}
+	log.Printf("Backup of %d SLOs written to: %s", len(slos), *output)
+}
+
+// writeBackup writes each slo definition to path/<slo id>.json, or into a .tar.gz archive at path
+func writeBackup(path string, slos []datadog.ServiceLevelObjective) error {
+	if !strings.HasSuffix(path, ".tar.gz") {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return err
+		}
+		for _, slo := range slos {
+			if _, err := exportDefinition(path, slo); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	archive := tar.NewWriter(gz)
+	for _, slo := range slos {
+		content, err := json.MarshalIndent(slo, "", "  ")
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{Name: slo.GetId() + ".json", Mode: 0644, Size: int64(len(content)), ModTime: runClock.Now()}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := archive.Write(content); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// readBackup reads the slo definitions of a backup directory or .tar.gz archive
+func readBackup(path string) ([]datadog.ServiceLevelObjective, error) {
+	var slos []datadog.ServiceLevelObjective
+	add := func(content []byte) error {
+		var slo datadog.ServiceLevelObjective
+		if err := json.Unmarshal(content, &slo); err != nil {
+			return err
+		}
+		slos = append(slos, slo)
+		return nil
+	}
+
+	if !strings.HasSuffix(path, ".tar.gz") {
+		files, err := filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if err := add(content); err != nil {
+				return nil, err
+			}
+		}
+		return slos, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return slos, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(header.Name, ".json") {
+			continue
+		}
+		content, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(content); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// runRestore recreates the slos of a backup that no longer exist and updates the ones that changed,
+// writing the changes to stdout as csv, -dry-run previews them without making them
+func runRestore(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	from := fs.String("from", "slo_backup", "backup directory or .tar.gz archive to restore")
+	var ids stringList
+	fs.Var(&ids, "ids", "comma separated slo ids to restore (default all in the backup)")
+	dryRun := fs.Bool("dry-run", false, "only write the changes restoring would make")
+	parseFlags(fs, args)
+	if !*dryRun {
+		requireWritable("restore")
+	}
+
+	backup, err := readBackup(*from)
+	if err != nil {
+		fatalf("Unable to read backup: %s, err: %s", *from, err)
+	}
+	if len(ids) > 0 {
+		var selected []datadog.ServiceLevelObjective
+		for _, slo := range backup {
+			if ids.contains(slo.GetId()) {
+				selected = append(selected, slo)
+			}
+		}
+		backup = selected
+	}
+
+	backupIDs := make([]string, 0, len(backup))
+	for _, slo := range backup {
+		backupIDs = append(backupIDs, slo.GetId())
+	}
+	existing, err := getSLOsByID(ctx, backupIDs)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	current := map[string]datadog.ServiceLevelObjective{}
+	for _, slo := range existing {
+		current[slo.GetId()] = slo
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	cols := []string{"name", "slo_id", "action", "field", "before", "after"}
+	if err := writer.Write(cols); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+	write := func(data ...string) {
+		if err := writer.Write(data); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+	}
+
+	apiClient := newAPIClient()
+	created, updated := 0, 0
+	stop := func(err error) {
+		writer.Flush()
+		fatalf("Restore stopped after %d SLOs created and %d updated, err: %s", created, updated, err)
+	}
+	for _, slo := range backup {
+		before, found := current[slo.GetId()]
+		if !found {
+			newID := ""
+			var sleepErr error
+			if !*dryRun {
+				resp, _, err := apiClient.ServiceLevelObjectivesApi.CreateSLO(ctx, sloRequest(slo))
+				if err != nil {
+					fatalf("Error when calling `ServiceLevelObjectivesApi.CreateSLO` s: %s, err: %v\n", slo.GetId(), err)
+				}
+				if data := resp.GetData(); len(data) > 0 {
+					newID = data[0].GetId()
+				}
+				sleepErr = sleepContext(ctx, options.sleep)
+			}
+			// deleted slos can not be recreated with their id, the after value is the new id
+			write(slo.GetName(), slo.GetId(), "create", "slo_id", slo.GetId(), newID)
+			created++
+			if sleepErr != nil {
+				stop(sleepErr)
+			}
+			continue
+		}
+
+		changed := false
+		for _, field := range sloDefinitionFields {
+			if b, a := field.value(before), field.value(slo); b != a {
+				write(slo.GetName(), slo.GetId(), "update", field.name, b, a)
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		var sleepErr error
+		if !*dryRun {
+			if _, _, err := apiClient.ServiceLevelObjectivesApi.UpdateSLO(ctx, slo.GetId(), sloDefinition(slo)); err != nil {
+				fatalf("Error when calling `ServiceLevelObjectivesApi.UpdateSLO` s: %s, err: %v\n", slo.GetId(), err)
+			}
+			sleepErr = sleepContext(ctx, options.sleep)
+		}
+		updated++
+		if sleepErr != nil {
+			stop(sleepErr)
+		}
+	}
+
+	if *dryRun {
+		log.Printf("Dry run - %d SLOs would be created and %d updated", created, updated)
+		return
+	}
+	log.Printf("Done - %d SLOs created and %d updated", created, updated)
+}
+
+// sloDefinition returns the user editable fields of the slo
+func sloDefinition(slo datadog.ServiceLevelObjective) datadog.ServiceLevelObjective {
+	slo.CreatedAt = nil
+	slo.Creator = nil
+	slo.ModifiedAt = nil
+	slo.MonitorTags = nil
+	return slo
+}
+
+// sloRequest returns the create request of the slo definition
+func sloRequest(slo datadog.ServiceLevelObjective) datadog.ServiceLevelObjectiveRequest {
+	return datadog.ServiceLevelObjectiveRequest{
+		Description: slo.Description,
+		Groups:      slo.Groups,
+		MonitorIds:  slo.MonitorIds,
+		Name:        slo.Name,
+		Query:       slo.Query,
+		Tags:        slo.Tags,
+		Thresholds:  slo.Thresholds,
+		Type:        slo.Type,
+	}
+}
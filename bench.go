@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// benchLatencies are the latencies and errors of the calls to an endpoint
+type benchLatencies struct {
+	latencies []time.Duration
+	errors    int
+}
+
+// runBench issues history calls for a sample of the slos matching the tag query and writes the latency
+// percentiles per endpoint to stdout, to tune -sleep and other rate settings before a full run
+func runBench(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	calls := fs.Int("calls", 50, "number of history calls")
+	sampleSize := fs.Int64("slos", 5, "number of slos the history calls are spread over")
+	concurrency := fs.Int("concurrency", 1, "number of history calls in flight")
+	parseFlags(fs, args)
+
+	if *calls < 1 || *sampleSize < 1 || *concurrency < 1 {
+		fatalf("Invalid bench, -calls, -slos and -concurrency must be at least 1")
+	}
+
+	apiClient := newAPIClient()
+	results := map[string]*benchLatencies{"ListSLOs": {}, "GetSLOHistory": {}}
+
+	start := runClock.Now()
+	resp, _, err := apiClient.ServiceLevelObjectivesApi.ListSLOs(ctx, datadog.ListSLOsOptionalParameters{
+		Limit:     sampleSize,
+		TagsQuery: &options.tagQuery,
+	})
+	results["ListSLOs"].latencies = append(results["ListSLOs"].latencies, since(start))
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	var slos []datadog.ServiceLevelObjective
+	for _, slo := range resp.GetData() {
+		if len(slo.Thresholds) > 0 {
+			slos = append(slos, slo)
+		}
+	}
+	if len(slos) == 0 {
+		fatalf("No SLOs with thresholds found for tag query: %q", options.tagQuery)
+	}
+
+	log.Printf("Benchmarking %d history calls over %d SLOs, %d in flight ...", *calls, len(slos), *concurrency)
+	now := runClock.Now().UTC()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	next := make(chan int)
+	history := results["GetSLOHistory"]
+	benchStart := runClock.Now()
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range next {
+				slo := slos[n%len(slos)]
+				threshold := slo.Thresholds[0]
+				from, to, err := getSLOTimeSpanFromTimeframe(threshold.Timeframe, now)
+				if err != nil {
+					from, to = now.Add(-ThirtyDays), now
+				}
+				start := runClock.Now()
+				_, err = getSLOHistory(ctx, apiClient, slo, threshold, from, to)
+				latency := since(start)
+				mu.Lock()
+				history.latencies = append(history.latencies, latency)
+				if err != nil {
+					history.errors++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for n := 0; n < *calls; n++ {
+		next <- n
+	}
+	close(next)
+	wg.Wait()
+	elapsed := since(benchStart)
+
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	cols := []string{"endpoint", "calls", "errors", "p50_ms", "p90_ms", "p99_ms", "max_ms", "calls_per_second"}
+	if err := writer.Write(cols); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+	for _, endpoint := range []string{"ListSLOs", "GetSLOHistory"} {
+		r := results[endpoint]
+		rate := ""
+		if endpoint == "GetSLOHistory" && elapsed > 0 {
+			rate = fmt.Sprintf("%.2f", float64(len(r.latencies))/elapsed.Seconds())
+		}
+		data := []string{
+			endpoint,
+			fmt.Sprintf("%d", len(r.latencies)),
+			fmt.Sprintf("%d", r.errors),
+			milliseconds(percentile(r.latencies, 50)),
+			milliseconds(percentile(r.latencies, 90)),
+			milliseconds(percentile(r.latencies, 99)),
+			milliseconds(percentile(r.latencies, 100)),
+			rate,
+		}
+		if err := writer.Write(data); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+	}
+}
+
+// percentile returns the nearest rank percentile p (0-100] of the latencies
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// milliseconds formats the duration in milliseconds
+func milliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// policyActionColumn holds the action of the first error budget policy rule matching the row
+const policyActionColumn = "policy_action"
+
+// budgetPolicyRule recommends an action for rows matching its expression
+// e.g {"when": "error_budget_consumed > 50 && month_elapsed < 50", "action": "feature freeze"}
+type budgetPolicyRule struct {
+	When   string `json:"when"`
+	Action string `json:"action"`
+
+	expr *expression
+}
+
+// parseBudgetPolicy parses the rule expressions, checking they only refer to known fields
+func parseBudgetPolicy(rules []*budgetPolicyRule) error {
+	header := reportHeader()
+	for _, rule := range rules {
+		if rule.Action == "" {
+			return fmt.Errorf("budget_policy: rule without an action: %s", rule.When)
+		}
+		expr, err := parseExpression(rule.When)
+		if err != nil {
+			return fmt.Errorf("budget_policy %s: %s", rule.Action, err)
+		}
+		if err := expr.checkFields(derivedLookup(header, header)); err != nil {
+			return fmt.Errorf("budget_policy %s: %s", rule.Action, err)
+		}
+		rule.expr = expr
+	}
+	return nil
+}
+
+// policyWriter appends the policy action to each record before writing it to the next writer,
+// keeping the teams each action is recommended for
+type policyWriter struct {
+	next   reportWriter
+	rules  []*budgetPolicyRule
+	header []string
+	teams  map[string]map[string]bool
+}
+
+// newPolicyWriter returns a policy writer evaluating the rules writing records to next
+func newPolicyWriter(next reportWriter, rules []*budgetPolicyRule) *policyWriter {
+	return &policyWriter{next: next, rules: rules, teams: map[string]map[string]bool{}}
+}
+
+// Write appends the policy action column to the header, and the first matching rule's action to other records
+func (w *policyWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = append([]string{}, record...)
+		return w.next.Write(append(w.header, policyActionColumn))
+	}
+
+	lookup := derivedLookup(w.header, record)
+	action := ""
+	for _, rule := range w.rules {
+		matched, err := rule.expr.evalBool(lookup)
+		if err != nil {
+			continue
+		}
+		if matched {
+			action = rule.Action
+			break
+		}
+	}
+	if action != "" {
+		if w.teams[action] == nil {
+			w.teams[action] = map[string]bool{}
+		}
+		w.teams[action][recordTeam(lookup)] = true
+	}
+	return w.next.Write(append(append([]string{}, record...), action))
+}
+
+// Flush flushes the next writer
+func (w *policyWriter) Flush() {
+	w.next.Flush()
+}
+
+// logSummary logs the teams each policy action is recommended for
+func (w *policyWriter) logSummary() {
+	for _, rule := range w.rules {
+		teams, found := w.teams[rule.Action]
+		if !found {
+			continue
+		}
+		names := make([]string, 0, len(teams))
+		for team := range teams {
+			names = append(names, team)
+		}
+		sort.Strings(names)
+		log.Printf("Policy %q recommended for teams: %s", rule.Action, strings.Join(names, ", "))
+	}
+}
+
+// recordTeam returns the record's team from the hierarchy, -resolve-teams or -tag-columns team columns
+func recordTeam(lookup fieldLookup) string {
+	for _, col := range []string{"team", "team_name", tagColumn("team")} {
+		if team, _ := lookup(col); team != "" {
+			return team
+		}
+	}
+	return "(unknown)"
+}
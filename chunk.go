@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// getChunkedSLOHistory returns the slo history of the window, fetched in -history-chunk long chunks merged
+// into one response when the window is longer, since long single history calls time out for some slos
+func getChunkedSLOHistory(
+	ctx context.Context,
+	apiClient *datadog.APIClient,
+	slo datadog.ServiceLevelObjective,
+	threshold datadog.SLOThreshold,
+	from, to time.Time,
+) (*datadog.SLOHistoryResponse, error) {
+	chunk := options.historyChunk.duration()
+	if chunk == 0 || to.Sub(from) <= chunk {
+		return getSLOHistory(ctx, apiClient, slo, threshold, from, to)
+	}
+
+	var chunks []historyChunk
+	for start := from; start.Before(to); start = start.Add(chunk) {
+		end := start.Add(chunk)
+		if end.After(to) {
+			end = to
+		}
+		history, err := getSLOHistory(ctx, apiClient, slo, threshold, start, end)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, historyChunk{history: history, duration: end.Sub(start)})
+	}
+	return mergeHistoryChunks(chunks, threshold, from, to), nil
+}
+
+// historyChunk is the history of a chunk of a window
+type historyChunk struct {
+	history  *datadog.SLOHistoryResponse
+	duration time.Duration
+}
+
+// mergeHistoryChunks merges the chunk histories, the sli of metric slos is the good over total events of all chunks,
+// otherwise (e.g monitor slos and groups) the chunk slis weighted by the chunk duration, chunks without data are
+// left out of the sli
+func mergeHistoryChunks(chunks []historyChunk, threshold datadog.SLOThreshold, from, to time.Time) *datadog.SLOHistoryResponse {
+	merged := *chunks[0].history
+	data := merged.GetData()
+	data.SetFromTs(from.UTC().Unix())
+	data.SetToTs(to.UTC().Unix())
+
+	overall := make([]sliChunk, 0, len(chunks))
+	groups := map[string][]sliChunk{}
+	var groupOrder []string
+	var series *datadog.SLOHistoryMetrics
+	for _, c := range chunks {
+		chunkData := c.history.GetData()
+		overall = append(overall, sliChunk{data: chunkData.GetOverall(), duration: c.duration})
+		for _, group := range chunkData.GetGroups() {
+			name := group.GetGroup() + "\x00" + group.GetName()
+			if _, found := groups[name]; !found {
+				groupOrder = append(groupOrder, name)
+			}
+			groups[name] = append(groups[name], sliChunk{data: group, duration: c.duration})
+		}
+		series = mergeSeries(series, chunkData.Series)
+	}
+
+	overallData := mergeSLIData(overall, threshold)
+	if series != nil && series.Denominator.Sum > 0 {
+		overallData.SetSliValue(series.Numerator.Sum / series.Denominator.Sum * 100)
+		overallData.SetErrorBudgetRemaining(map[string]float64{"custom": errorBudgetRemaining(overallData.GetSliValue(), threshold.Target)})
+	}
+	data.Overall = &overallData
+	data.Series = series
+	if len(groupOrder) > 0 {
+		mergedGroups := make([]datadog.SLOHistorySLIData, 0, len(groupOrder))
+		for _, name := range groupOrder {
+			mergedGroups = append(mergedGroups, mergeSLIData(groups[name], threshold))
+		}
+		data.SetGroups(mergedGroups)
+	}
+	merged.Data = &data
+	return &merged
+}
+
+// sliChunk is the sli data of a chunk
+type sliChunk struct {
+	data     datadog.SLOHistorySLIData
+	duration time.Duration
+}
+
+// mergeSLIData returns the sli data of the first chunk with the duration weighted sli of the chunks with an sli
+// and its error budget remaining, the sli is left unset when no chunk has one (e.g no data)
+func mergeSLIData(chunks []sliChunk, threshold datadog.SLOThreshold) datadog.SLOHistorySLIData {
+	merged := chunks[0].data
+	merged.History = nil
+	var weighted, total float64
+	for _, c := range chunks {
+		if sli, ok := c.data.GetSliValueOk(); ok {
+			weighted += *sli * c.duration.Seconds()
+			total += c.duration.Seconds()
+		}
+	}
+	if total == 0 {
+		merged.SliValue = nil
+		return merged
+	}
+	sli := weighted / total
+	merged.SetSliValue(sli)
+	merged.SetErrorBudgetRemaining(map[string]float64{"custom": errorBudgetRemaining(sli, threshold.Target)})
+	return merged
+}
+
+// mergeSeries adds the good and total event counts of the chunk to the merged series
+func mergeSeries(merged, chunk *datadog.SLOHistoryMetrics) *datadog.SLOHistoryMetrics {
+	if chunk == nil {
+		return merged
+	}
+	if merged == nil {
+		copied := *chunk
+		copied.Times = append([]float64(nil), chunk.Times...)
+		copied.Numerator.Values = append([]float64(nil), chunk.Numerator.Values...)
+		copied.Denominator.Values = append([]float64(nil), chunk.Denominator.Values...)
+		return &copied
+	}
+	merged.Times = append(merged.Times, chunk.Times...)
+	merged.Numerator.Sum += chunk.Numerator.Sum
+	merged.Numerator.Count += chunk.Numerator.Count
+	merged.Numerator.Values = append(merged.Numerator.Values, chunk.Numerator.Values...)
+	merged.Denominator.Sum += chunk.Denominator.Sum
+	merged.Denominator.Count += chunk.Denominator.Count
+	merged.Denominator.Values = append(merged.Denominator.Values, chunk.Denominator.Values...)
+	return merged
+}
+
+// errorBudgetRemaining returns the percentage of the error budget of the target left at the sli
+func errorBudgetRemaining(sli, target float64) float64 {
+	if target >= 100 {
+		if sli >= 100 {
+			return 100
+		}
+		return 0
+	}
+	return (sli - target) / (100 - target) * 100
+}
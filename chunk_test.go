@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestMergeHistoryChunks(t *testing.T) {
+	threshold := datadog.SLOThreshold{Target: 99.9, Timeframe: datadog.SLOTIMEFRAME_THIRTY_DAYS}
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(40 * OneDay)
+	sli := func(v float64) *float64 { return &v }
+	events := func(good, total float64) *datadog.SLOHistoryMetrics {
+		return &datadog.SLOHistoryMetrics{
+			Numerator:   datadog.SLOHistoryMetricsSeries{Sum: good},
+			Denominator: datadog.SLOHistoryMetricsSeries{Sum: total},
+		}
+	}
+	chunk := func(days int, sli *float64, series *datadog.SLOHistoryMetrics, groups ...datadog.SLOHistorySLIData) historyChunk {
+		data := &datadog.SLOHistoryResponseData{Overall: &datadog.SLOHistorySLIData{SliValue: sli}, Series: series}
+		if len(groups) > 0 {
+			data.Groups = &groups
+		}
+		return historyChunk{history: &datadog.SLOHistoryResponse{Data: data}, duration: time.Duration(days) * OneDay}
+	}
+	group := func(name string, sli *float64) datadog.SLOHistorySLIData {
+		return datadog.SLOHistorySLIData{Name: &name, SliValue: sli}
+	}
+	tests := []struct {
+		name   string
+		chunks []historyChunk
+		sli    *float64
+		groups map[string]*float64
+	}{
+		{"single chunk", []historyChunk{chunk(40, sli(99.5), nil)}, sli(99.5), nil},
+		{"partial last chunk weighted by duration", []historyChunk{chunk(30, sli(99), nil), chunk(10, sli(100), nil)}, sli(99.25), nil},
+		{"chunk without data left out", []historyChunk{chunk(30, sli(99), nil), chunk(10, nil, nil)}, sli(99), nil},
+		{"empty chunk response left out", []historyChunk{chunk(30, sli(99), nil), {history: &datadog.SLOHistoryResponse{}, duration: 10 * OneDay}}, sli(99), nil},
+		{"no chunk with data", []historyChunk{chunk(30, nil, nil), chunk(10, nil, nil)}, nil, nil},
+		{
+			"metric slo good over total events",
+			[]historyChunk{chunk(30, sli(99), events(990, 1000)), chunk(10, sli(100), events(3000, 3000))},
+			sli(99.75),
+			nil,
+		},
+		{"metric slo without events", []historyChunk{chunk(30, sli(99), events(0, 0)), chunk(10, sli(100), events(0, 0))}, sli(99.25), nil},
+		{
+			"groups merged by name",
+			[]historyChunk{
+				chunk(30, sli(99), nil, group("eu", sli(98)), group("us", sli(100))),
+				chunk(10, sli(100), nil, group("us", sli(99)), group("ap", nil)),
+			},
+			sli(99.25),
+			map[string]*float64{"eu": sli(98), "us": sli(99.75), "ap": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := mergeHistoryChunks(tt.chunks, threshold, from, to)
+			data := merged.GetData()
+			if data.GetFromTs() != from.Unix() || data.GetToTs() != to.Unix() {
+				t.Errorf("mergeHistoryChunks() span = %d-%d, want %d-%d", data.GetFromTs(), data.GetToTs(), from.Unix(), to.Unix())
+			}
+			overall := data.GetOverall()
+			if !sameSLI(overall.SliValue, tt.sli) {
+				t.Errorf("mergeHistoryChunks() sli = %v, want %v", optionalSLI(overall.SliValue), optionalSLI(tt.sli))
+			}
+			if tt.sli != nil {
+				want := errorBudgetRemaining(*tt.sli, threshold.Target)
+				if got := overall.GetErrorBudgetRemaining()["custom"]; math.Abs(got-want) > 1e-9 {
+					t.Errorf("mergeHistoryChunks() error budget remaining = %f, want %f", got, want)
+				}
+			}
+			groups := data.GetGroups()
+			if len(groups) != len(tt.groups) {
+				t.Fatalf("mergeHistoryChunks() has %d groups, want %d", len(groups), len(tt.groups))
+			}
+			for _, g := range groups {
+				if want := tt.groups[g.GetName()]; !sameSLI(g.SliValue, want) {
+					t.Errorf("mergeHistoryChunks() group %s sli = %v, want %v", g.GetName(), optionalSLI(g.SliValue), optionalSLI(want))
+				}
+			}
+		})
+	}
+}
+
+// sameSLI returns true when both slis are unset or equal
+func sameSLI(got, want *float64) bool {
+	if got == nil || want == nil {
+		return got == nil && want == nil
+	}
+	return math.Abs(*got-*want) < 1e-9
+}
+
+// optionalSLI returns the sli for messages, nil when unset
+func optionalSLI(sli *float64) interface{} {
+	if sli == nil {
+		return nil
+	}
+	return *sli
+}
+
+func TestErrorBudgetRemaining(t *testing.T) {
+	tests := []struct {
+		name        string
+		sli, target float64
+		want        float64
+	}{
+		{"half consumed", 99.95, 99.9, 50},
+		{"unused", 100, 99.9, 100},
+		{"exhausted", 99.9, 99.9, 0},
+		{"overspent", 99.8, 99.9, -100},
+		{"100 target met", 100, 100, 100},
+		{"100 target missed", 99.99, 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorBudgetRemaining(tt.sli, tt.target); math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("errorBudgetRemaining(%f, %f) = %f, want %f", tt.sli, tt.target, got, tt.want)
+			}
+		})
+	}
+}
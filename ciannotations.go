@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"slos/schema"
+)
+
+// ciAnnotation is the CI feedback of a report row: breached rows are errors, warning rows and rows whose history
+// could not be read are warnings
+type ciAnnotation struct {
+	level   string
+	check   string
+	title   string
+	message string
+}
+
+// rowAnnotation returns the annotation of a report row, false for rows that need none e.g OK
+func rowAnnotation(row map[string]string) (ciAnnotation, bool) {
+	window := strings.Join(nonEmpty(row["timeframe"], row["group"], row["period"]), " ")
+	link := fmt.Sprintf("https://app.%s/slo?slo_id=%s", datadogSite(), row["slo_id"])
+	switch {
+	case row["error (only if applicable)"] != "" && row["status"] != schema.StatusNoData:
+		return ciAnnotation{level: "warning", check: "slo-error", title: "SLO history unavailable: " + row["name"],
+			message: fmt.Sprintf("%s %s: %s (%s) %s", row["name"], window, row["error (only if applicable)"], row["error_type"], link)}, true
+	case row["status"] == schema.StatusBreached:
+		return ciAnnotation{level: "error", check: "slo-breached", title: "SLO breached: " + row["name"],
+			message: fmt.Sprintf("%s %s: SLI %s below target %s, %s%% of the error budget consumed %s",
+				row["name"], window, row["overall_status"], row["target"], row["error_budget_consumed"], link)}, true
+	case row["status"] == schema.StatusWarning:
+		return ciAnnotation{level: "warning", check: "slo-warning", title: "SLO warning: " + row["name"],
+			message: fmt.Sprintf("%s %s: SLI %s below warning %s, %s%% of the error budget consumed %s",
+				row["name"], window, row["overall_status"], row["warning"], row["error_budget_consumed"], link)}, true
+	}
+	return ciAnnotation{}, false
+}
+
+// nonEmpty returns the values that are not empty
+func nonEmpty(values ...string) []string {
+	var kept []string
+	for _, value := range values {
+		if value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+// openGitHubOutput returns an output printing GitHub Actions workflow commands to stdout, an ::error or ::warning
+// annotation per breached, warning or failed row
+func openGitHubOutput(string) (*output, error) {
+	return &output{format: "github", writer: &githubWriter{out: os.Stdout}, close: func() error { return nil }}, nil
+}
+
+// githubWriter prints the annotations of records as workflow commands as they come
+type githubWriter struct {
+	out    io.Writer
+	header []string
+}
+
+// githubEscaper escapes workflow command messages
+var githubEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// githubPropertyEscaper escapes workflow command property values
+var githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// Write keeps the header and prints the annotation of other records
+func (w *githubWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		return nil
+	}
+	annotation, found := rowAnnotation(recordMap(w.header, record))
+	if !found {
+		return nil
+	}
+	_, err := fmt.Fprintf(w.out, "::%s title=%s::%s\n", annotation.level,
+		githubPropertyEscaper.Replace(annotation.title), githubEscaper.Replace(annotation.message))
+	return err
+}
+
+// Flush is a no-op, annotations are printed as they come
+func (w *githubWriter) Flush() {}
+
+// gitlabIssue is an issue of a GitLab code quality report
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+// gitlabLocation is where a code quality issue is, slos are not files so the path is datadog/slo/SLO_ID
+type gitlabLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// gitlabSeverities are the code quality severities of annotation levels
+var gitlabSeverities = map[string]string{"error": "critical", "warning": "minor"}
+
+// openGitLabOutput creates a GitLab code quality json report, encrypted if enabled, with an issue per breached,
+// warning or failed row, written once all rows are received
+func openGitLabOutput(path string) (*output, error) {
+	file, err := createReportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := &gitlabWriter{issues: []gitlabIssue{}}
+	return &output{format: "gitlab", path: path, writer: writer, close: func() error {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(writer.issues); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}}, nil
+}
+
+// gitlabWriter collects the annotations of records as code quality issues
+type gitlabWriter struct {
+	header []string
+	issues []gitlabIssue
+}
+
+// Write keeps the header and adds the annotation of other records as an issue, fingerprinted by the slo and window
+// so GitLab tracks an issue across pipelines
+func (w *gitlabWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		return nil
+	}
+	row := recordMap(w.header, record)
+	annotation, found := rowAnnotation(row)
+	if !found {
+		return nil
+	}
+	issue := gitlabIssue{
+		Description: annotation.message,
+		CheckName:   annotation.check,
+		Fingerprint: sha256Hex([]byte(strings.Join([]string{annotation.check, row["slo_id"], row["timeframe"], row["group"], row["period"]}, "\n"))),
+		Severity:    gitlabSeverities[annotation.level],
+		Location:    gitlabLocation{Path: "datadog/slo/" + row["slo_id"]},
+	}
+	issue.Location.Lines.Begin = 1
+	w.issues = append(w.issues, issue)
+	return nil
+}
+
+// Flush is a no-op, the report is written when the output is closed
+func (w *gitlabWriter) Flush() {}
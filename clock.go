@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// clock tells the time and sleeps, time dependent logic (windows, calendar alignment, pauses between calls) uses
+// runClock so it can be driven by a fixed clock in tests
+type clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the wall clock
+type systemClock struct{}
+
+// Now returns the current time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses for d
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// After returns a channel receiving the time after d
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// runClock is the clock of the run
+var runClock clock = systemClock{}
+
+// since returns the time elapsed since t on the run clock
+func since(t time.Time) time.Duration {
+	return runClock.Now().Sub(t)
+}
+
+// sleepContext pauses for d on the run clock, returning the context error as soon as it is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-runClock.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock stopped at now, sleeps advance it instead of pausing
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// useFakeClock makes a fake clock at now the run clock for the test
+func useFakeClock(t *testing.T, now time.Time) *fakeClock {
+	c := &fakeClock{now: now}
+	saved := runClock
+	runClock = c
+	t.Cleanup(func() { runClock = saved })
+	return c
+}
+
+// Now returns the fake time
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep advances the fake time by d
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// After advances the fake time by d and returns a channel holding it
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Sleep(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func TestSleepContext(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		d       time.Duration
+		wantErr bool
+		elapsed time.Duration
+	}{
+		{"sleeps", context.Background(), time.Minute, false, time.Minute},
+		{"no pause", context.Background(), 0, false, 0},
+		{"cancelled", cancelled, time.Minute, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := useFakeClock(t, start)
+			if err := sleepContext(tt.ctx, tt.d); (err != nil) != tt.wantErr {
+				t.Errorf("sleepContext() = %v, want error %t", err, tt.wantErr)
+			}
+			if got := since(start); got != tt.elapsed {
+				t.Errorf("sleepContext() slept %s, want %s", got, tt.elapsed)
+			}
+			if clock.Now() != start.Add(tt.elapsed) {
+				t.Errorf("Now() = %s, want %s", clock.Now(), start.Add(tt.elapsed))
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// clonedFromTag is the tag key of cloned slos holding the source slo id, so cloning again updates them
+const clonedFromTag = "cloned_from"
+
+// destinationContext returns the run context with the destination org credentials
+// from DEST_DD_API_KEY, DEST_DD_APP_KEY and DEST_DD_SITE (default datadoghq.com)
+func destinationContext(ctx context.Context) (context.Context, error) {
+	apiKey, appKey := os.Getenv("DEST_DD_API_KEY"), os.Getenv("DEST_DD_APP_KEY")
+	if apiKey == "" || appKey == "" {
+		return nil, fmt.Errorf("DEST_DD_API_KEY and DEST_DD_APP_KEY must be set")
+	}
+	ctx = context.WithValue(ctx, datadog.ContextAPIKeys, map[string]datadog.APIKey{
+		"apiKeyAuth": {Key: apiKey},
+		"appKeyAuth": {Key: appKey},
+	})
+	if site := os.Getenv("DEST_DD_SITE"); site != "" {
+		ctx = context.WithValue(ctx, datadog.ContextServerVariables, map[string]string{"site": site})
+	}
+	return ctx, nil
+}
+
+// runClone copies the definitions of the slos matching the tag query to the destination org,
+// remapping monitor ids, slos cloned before (tagged cloned_from:<source slo id>) are updated
+func runClone(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("clone", flag.ContinueOnError)
+	mappingPath := fs.String("monitor-mapping", "", "path of a json file mapping source to destination monitor ids e.g {\"123\": 456}, required for monitor slos")
+	dryRun := fs.Bool("dry-run", false, "only write the slos that would be created or updated")
+	parseFlags(fs, args)
+	if !*dryRun {
+		requireWritable("clone")
+	}
+
+	monitorMapping := map[string]int64{}
+	if *mappingPath != "" {
+		content, err := ioutil.ReadFile(*mappingPath)
+		if err != nil {
+			fatalf("Unable to read monitor mapping: %s, err: %s", *mappingPath, err)
+		}
+		if err := json.Unmarshal(content, &monitorMapping); err != nil {
+			fatalf("Unable to parse monitor mapping: %s, err: %s", *mappingPath, err)
+		}
+	}
+	destCtx, err := destinationContext(ctx)
+	if err != nil {
+		fatalf("Unable to configure destination org, err: %s", err)
+	}
+
+	slos, err := getSLODefinitions(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	destSLOs, err := listOrgSLOs(destCtx, options.limit, "")
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs` for the destination org: %v\n", err)
+	}
+	cloned := map[string]string{}
+	for _, slo := range destSLOs {
+		if source := sloTagValue(slo, clonedFromTag); source != "" {
+			cloned[source] = slo.GetId()
+		}
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	cols := []string{"name", "slo_id", "action", "destination_slo_id", "error (only if applicable)"}
+	if err := writer.Write(cols); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+
+	apiClient := newAPIClient()
+	failed := 0
+	for counter, slo := range slos {
+		destID, exists := cloned[slo.GetId()]
+		action := "create"
+		if exists {
+			action = "update"
+		}
+		var stopped error
+		clone, err := cloneDefinition(slo, monitorMapping)
+		if err == nil && !*dryRun {
+			log.Printf("(%d of %d) Cloning s: %s", counter+1, len(slos), slo.GetId())
+			if exists {
+				_, _, err = apiClient.ServiceLevelObjectivesApi.UpdateSLO(destCtx, destID, clone)
+			} else {
+				var resp datadog.SLOListResponse
+				resp, _, err = apiClient.ServiceLevelObjectivesApi.CreateSLO(destCtx, sloRequest(clone))
+				if data := resp.GetData(); err == nil && len(data) > 0 {
+					destID = data[0].GetId()
+				}
+			}
+			stopped = sleepContext(ctx, options.sleep)
+		}
+		errStr := ""
+		if err != nil {
+			log.Printf("Unable to clone s: %s, err: %s", slo.GetId(), err)
+			errStr = err.Error()
+			failed++
+		}
+		if err := writer.Write([]string{slo.GetName(), slo.GetId(), action, destID, errStr}); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+		if stopped != nil {
+			writer.Flush()
+			fatalf("Clone stopped after %d of %d SLOs, err: %s", counter+1, len(slos), stopped)
+		}
+	}
+	log.Printf("Done - %d of %d SLOs cloned", len(slos)-failed, len(slos))
+}
+
+// cloneDefinition returns the slo definition for the destination org, tagged with its source slo id
+func cloneDefinition(slo datadog.ServiceLevelObjective, monitorMapping map[string]int64) (datadog.ServiceLevelObjective, error) {
+	clone := sloDefinition(slo)
+	clone.Id = nil
+	if ids, ok := slo.GetMonitorIdsOk(); ok {
+		mapped := make([]int64, 0, len(*ids))
+		for _, id := range *ids {
+			destID, found := monitorMapping[strconv.FormatInt(id, 10)]
+			if !found {
+				return clone, fmt.Errorf("no destination monitor mapped for monitor %d", id)
+			}
+			mapped = append(mapped, destID)
+		}
+		clone.SetMonitorIds(mapped)
+	}
+	tags := []string{clonedFromTag + ":" + slo.GetId()}
+	for _, tag := range slo.GetTags() {
+		if !strings.HasPrefix(tag, clonedFromTag+":") {
+			tags = append(tags, tag)
+		}
+	}
+	clone.SetTags(tags)
+	return clone, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// historyKey identifies a history call, calls with the same key get the same response
+type historyKey struct {
+	sloID    string
+	from, to int64
+	target   float64
+}
+
+// historyCall is a history call in flight or done, done is closed once resp and err are set
+type historyCall struct {
+	done chan struct{}
+	resp *datadog.SLOHistoryResponse
+	err  error
+}
+
+// historyCoalescer makes a single api call for the history requested more than once e.g a -window equal to an slo
+// timeframe, the response is shared by the requesters, which only read it
+type historyCoalescer struct {
+	mu        sync.Mutex
+	calls     map[historyKey]*historyCall
+	coalesced int
+}
+
+// historyCalls coalesces the history calls of the report, it is nil (calls are not coalesced) for subcommands
+var historyCalls *historyCoalescer
+
+// newHistoryCoalescer returns an empty history coalescer
+func newHistoryCoalescer() *historyCoalescer {
+	return &historyCoalescer{calls: map[historyKey]*historyCall{}}
+}
+
+// do returns the response of the call with the key, calling fetch only if it was not called before or is not in flight
+func (c *historyCoalescer) do(key historyKey, fetch func() (*datadog.SLOHistoryResponse, error)) (*datadog.SLOHistoryResponse, error) {
+	if c == nil {
+		return fetch()
+	}
+	c.mu.Lock()
+	if call, found := c.calls[key]; found {
+		c.coalesced++
+		c.mu.Unlock()
+		<-call.done
+		return call.resp, call.err
+	}
+	call := &historyCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	call.resp, call.err = fetch()
+	close(call.done)
+	return call.resp, call.err
+}
+
+// reset forgets the calls made so far, the report resets it after each slo so responses are not kept for the run
+func (c *historyCoalescer) reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = map[historyKey]*historyCall{}
+}
+
+// coalescedCalls returns the number of history calls answered with the response of another call
+func (c *historyCoalescer) coalescedCalls() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.coalesced
+}
+
+// newHistoryKey returns the key of the history call of the slo threshold between from and to
+func newHistoryKey(slo datadog.ServiceLevelObjective, threshold datadog.SLOThreshold, from, to time.Time) historyKey {
+	return historyKey{sloID: slo.GetId(), from: from.UTC().Unix(), to: to.UTC().Unix(), target: threshold.Target}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestHistoryCoalescerConcurrentCalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		callers   int
+		err       error
+		coalesced int
+	}{
+		{"single caller", 1, nil, 0},
+		{"identical calls in flight", 20, nil, 19},
+		{"failed call shared", 5, errors.New("rate limited"), 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newHistoryCoalescer()
+			key := historyKey{sloID: "abc", from: 1, to: 2, target: 99.9}
+			want := &datadog.SLOHistoryResponse{}
+			var fetches int64
+			release := make(chan struct{})
+			fetch := func() (*datadog.SLOHistoryResponse, error) {
+				atomic.AddInt64(&fetches, 1)
+				<-release
+				return want, tt.err
+			}
+
+			responses := make([]*datadog.SLOHistoryResponse, tt.callers)
+			errs := make([]error, tt.callers)
+			var wg sync.WaitGroup
+			for i := 0; i < tt.callers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					responses[i], errs[i] = c.do(key, fetch)
+				}(i)
+			}
+			// the fetch is held until every other caller waits on it
+			deadline := time.Now().Add(5 * time.Second)
+			for c.coalescedCalls() < tt.coalesced {
+				if time.Now().After(deadline) {
+					t.Fatalf("coalescedCalls() = %d, want %d callers waiting", c.coalescedCalls(), tt.coalesced)
+				}
+				time.Sleep(time.Millisecond)
+			}
+			close(release)
+			wg.Wait()
+
+			if got := atomic.LoadInt64(&fetches); got != 1 {
+				t.Errorf("fetched %d times, want 1", got)
+			}
+			if got := c.coalescedCalls(); got != tt.coalesced {
+				t.Errorf("coalescedCalls() = %d, want %d", got, tt.coalesced)
+			}
+			for i := range responses {
+				if responses[i] != want || errs[i] != tt.err {
+					t.Errorf("caller %d got %p, %v, want %p, %v", i, responses[i], errs[i], want, tt.err)
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryCoalescerKeys(t *testing.T) {
+	keyA := historyKey{sloID: "abc", from: 1, to: 2, target: 99.9}
+	keyB := historyKey{sloID: "abc", from: 1, to: 2, target: 99}
+	tests := []struct {
+		name      string
+		coalescer *historyCoalescer
+		keys      []historyKey
+		reset     bool
+		fetches   int
+		coalesced int
+	}{
+		{"not coalesced for subcommands", nil, []historyKey{keyA, keyA}, false, 2, 0},
+		{"done call reused", newHistoryCoalescer(), []historyKey{keyA, keyA}, false, 1, 1},
+		{"different targets", newHistoryCoalescer(), []historyKey{keyA, keyB}, false, 2, 0},
+		{"forgotten after reset", newHistoryCoalescer(), []historyKey{keyA, keyA}, true, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			fetch := func() (*datadog.SLOHistoryResponse, error) {
+				fetches++
+				return &datadog.SLOHistoryResponse{}, nil
+			}
+			for _, key := range tt.keys {
+				tt.coalescer.do(key, fetch)
+				if tt.reset {
+					tt.coalescer.reset()
+				}
+			}
+			if fetches != tt.fetches {
+				t.Errorf("fetched %d times, want %d", fetches, tt.fetches)
+			}
+			if got := tt.coalescer.coalescedCalls(); got != tt.coalesced {
+				t.Errorf("coalescedCalls() = %d, want %d", got, tt.coalesced)
+			}
+		})
+	}
+}
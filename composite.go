@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+
+	"slos/schema"
+)
+
+// compositeSLO is a synthetic slo, a weighted combination of existing slos
+// e.g checkout journey = 0.5 * api + 0.3 * db + 0.2 * cdn
+type compositeSLO struct {
+	Name       string               `json:"name"`
+	Target     float64              `json:"target"`
+	Warning    *float64             `json:"warning"`
+	Components []compositeComponent `json:"components"`
+}
+
+// compositeComponent is a weighted slo of a composite slo
+type compositeComponent struct {
+	SLOID  string  `json:"slo_id"`
+	Weight float64 `json:"weight"`
+}
+
+// validateCompositeSLOs checks the composite slos have a name, a target and components with positive weights
+func validateCompositeSLOs(composites []*compositeSLO) error {
+	for _, composite := range composites {
+		if composite.Name == "" || composite.Target <= 0 || composite.Target > 100 {
+			return fmt.Errorf("composite_slos: %q needs a name and a target between 0 and 100", composite.Name)
+		}
+		if len(composite.Components) == 0 {
+			return fmt.Errorf("composite_slos: %s has no components", composite.Name)
+		}
+		for _, component := range composite.Components {
+			if component.SLOID == "" || component.Weight <= 0 {
+				return fmt.Errorf("composite_slos: %s components need a slo_id and a positive weight", composite.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// componentSLI is the sli of a component slo in a timeframe
+type componentSLI struct {
+	sli      float64
+	from, to time.Time
+}
+
+// compositeWriter keeps the overall slis of the composite components written to the next writer,
+// writing a row per composite slo and timeframe once the report is complete
+type compositeWriter struct {
+	next   reportWriter
+	header []string
+	// components are the slo ids of the composite components
+	components map[string]bool
+	// slis are the component slis by slo id and timeframe
+	slis       map[string]map[string]componentSLI
+	timeframes []string
+}
+
+// newCompositeWriter returns a composite writer of the composite slos writing records to next
+func newCompositeWriter(next reportWriter, composites []*compositeSLO) *compositeWriter {
+	components := map[string]bool{}
+	for _, composite := range composites {
+		for _, component := range composite.Components {
+			components[component.SLOID] = true
+		}
+	}
+	return &compositeWriter{next: next, components: components, slis: map[string]map[string]componentSLI{}}
+}
+
+// Write keeps the sli of component slo rows with an sli, other than group and period rows, and writes the record to next
+func (w *compositeWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		return w.next.Write(record)
+	}
+	lookup := recordLookup(w.header, record)
+	id, _ := lookup("slo_id")
+	group, _ := lookup("group")
+	period, _ := lookup("period")
+	sli, err := lookupNumber(lookup, "overall_status")
+	if !w.components[id] || group != "" || period != "" || err != nil {
+		return w.next.Write(record)
+	}
+	timeframe, _ := lookup("timeframe")
+	from, _ := lookupNumber(lookup, "from_ts")
+	to, _ := lookupNumber(lookup, "to_ts")
+	if w.slis[id] == nil {
+		w.slis[id] = map[string]componentSLI{}
+	}
+	if !stringList(w.timeframes).contains(timeframe) {
+		w.timeframes = append(w.timeframes, timeframe)
+	}
+	w.slis[id][timeframe] = componentSLI{sli: sli, from: time.Unix(int64(from), 0), to: time.Unix(int64(to), 0)}
+	return w.next.Write(record)
+}
+
+// Flush flushes the next writer
+func (w *compositeWriter) Flush() {
+	w.next.Flush()
+}
+
+// writeComposites writes a row per composite slo and timeframe a component was reported in, the sli is
+// the weighted average of the component slis, rows missing components are written with an error
+func (w *compositeWriter) writeComposites(writer reportWriter, composites []*compositeSLO) error {
+	sort.Strings(w.timeframes)
+	for _, composite := range composites {
+		slo := datadog.ServiceLevelObjective{Name: composite.Name}
+		slo.SetId("composite:" + composite.Name)
+		for _, timeframe := range w.timeframes {
+			row := reportRow{
+				slo:       slo,
+				threshold: datadog.SLOThreshold{Target: composite.Target, Warning: composite.Warning},
+				timeframe: timeframe,
+			}
+			var weighted, weights float64
+			var missing []string
+			for _, component := range composite.Components {
+				sli, found := w.slis[component.SLOID][timeframe]
+				if !found {
+					missing = append(missing, component.SLOID)
+					continue
+				}
+				weighted += sli.sli * component.Weight
+				weights += component.Weight
+				row.from, row.to = sli.from, sli.to
+			}
+			if len(missing) == len(composite.Components) {
+				continue
+			}
+			if len(missing) > 0 {
+				row.err = withErrorType(schema.ErrorNoData, fmt.Errorf("composite components missing: %s", strings.Join(missing, ", ")))
+			} else {
+				sli := weighted / weights
+				consumed := 100 - errorBudgetRemaining(sli, composite.Target)
+				row.sliValue, row.errorBudgetConsumed = &sli, &consumed
+			}
+			if err := writer.Write(row.values()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
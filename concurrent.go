@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// bufferWriter keeps the records written to it, to be written to the report in order later
+type bufferWriter struct {
+	records [][]string
+}
+
+// Write keeps the record
+func (b *bufferWriter) Write(record []string) error {
+	b.records = append(b.records, record)
+	return nil
+}
+
+// Flush is a no-op, records are kept until written to the report
+func (b *bufferWriter) Flush() {}
+
+// reportConcurrently runs the reports of an slo's windows, at most concurrency at once, each writing to its own
+// buffer, then writes the buffered rows to writer in the order of the windows. Windows not started once the error
+// policy stops the run or the context is done are skipped.
+func reportConcurrently(ctx context.Context, writer reportWriter, windows []func(reportWriter), concurrency int) {
+	if concurrency <= 1 || len(windows) <= 1 {
+		for _, report := range windows {
+			if options.errorPolicy.stop() || ctx.Err() != nil {
+				return
+			}
+			report(writer)
+		}
+		return
+	}
+
+	buffers := make([]*bufferWriter, len(windows))
+	inFlight := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, report := range windows {
+		buffers[i] = &bufferWriter{}
+		inFlight <- struct{}{}
+		if options.errorPolicy.stop() || ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(report func(reportWriter), buffer *bufferWriter) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			report(buffer)
+		}(report, buffers[i])
+	}
+	wg.Wait()
+
+	for _, buffer := range buffers {
+		if buffer == nil {
+			continue
+		}
+		for _, record := range buffer.records {
+			if err := writer.Write(record); err != nil {
+				fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+			}
+		}
+	}
+	writer.Flush()
+}
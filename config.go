@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// config holds the settings loaded from the -config json file
+var config struct {
+	// DerivedColumns are appended to the report, in order
+	DerivedColumns []*derivedColumn `json:"derived_columns"`
+	// Hierarchy maps slos into service, team and department columns, summarized in a rollup file
+	Hierarchy *hierarchyConfig `json:"hierarchy"`
+	// TagNormalization rewrites slo tags as they are loaded, before filtering and column extraction
+	TagNormalization *tagNormalization `json:"tag_normalization"`
+	// ColumnNames maps report column names to the header names written e.g {"error_budget_consumed": "EB Consumed (%)"}
+	ColumnNames map[string]string `json:"column_names"`
+	// GroupTargets maps slo ids to the targets of their groups e.g {"slo_id": {"env:prod": 99.95}}
+	GroupTargets map[string]map[string]float64 `json:"group_targets"`
+	// CompositeSLOs are written as synthetic rows, weighted combinations of existing slos
+	CompositeSLOs []*compositeSLO `json:"composite_slos"`
+	// Scorecard are the grading criteria of the scorecard subcommand
+	Scorecard *scorecardConfig `json:"scorecard"`
+	// BudgetPolicy rules recommend an action per row in a policy_action column, the first matching rule applies
+	BudgetPolicy []*budgetPolicyRule `json:"budget_policy"`
+	// TeamNotifications maps teams to where -team-reports sends them their own rows
+	TeamNotifications map[string]teamNotification `json:"team_notifications"`
+	// UnstableOperations enable or disable unstable api client operations e.g {"GetSLOHistory": true}, over defaultUnstableOperations
+	UnstableOperations map[string]bool `json:"unstable_operations"`
+}
+
+// loadConfig loads and validates the json config file
+func loadConfig(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return err
+	}
+	if err := parseDerivedColumns(config.DerivedColumns); err != nil {
+		return err
+	}
+	if err := validateCompositeSLOs(config.CompositeSLOs); err != nil {
+		return err
+	}
+	if err := parseBudgetPolicy(config.BudgetPolicy); err != nil {
+		return err
+	}
+	checkUnstableOperations(config.UnstableOperations)
+	header := reportHeader()
+	for name := range config.ColumnNames {
+		if _, found := recordLookup(header, header)(name); !found {
+			return fmt.Errorf("column_names: unknown column %s", name)
+		}
+	}
+	return nil
+}
+
+// rowColumns returns the report columns followed by the enabled optional columns, as written by reportRow.values
+func rowColumns() []string {
+	columns := append([]string{}, reportColumns...)
+	if options.resolveTeams {
+		columns = append(columns, "team_name", "team_handle")
+	}
+	if config.Hierarchy != nil {
+		columns = append(columns, hierarchyLevels...)
+	}
+	for _, key := range options.tagColumns {
+		columns = append(columns, tagColumn(key))
+	}
+	if sloOverrides != nil {
+		for _, key := range sloOverrides.labelKeys {
+			columns = append(columns, labelColumn(key))
+		}
+	}
+	if len(config.GroupTargets) > 0 {
+		columns = append(columns, groupTargetColumn)
+	}
+	if options.eventCounts {
+		columns = append(columns, eventColumns...)
+	}
+	if options.downtimes {
+		columns = append(columns, downtimeColumn)
+	}
+	if options.muteStatus {
+		columns = append(columns, muteColumns...)
+	}
+	if options.incidents {
+		columns = append(columns, incidentColumn)
+	}
+	if contractTargets != nil {
+		columns = append(columns, slaColumns...)
+	}
+	if options.fastBurn {
+		columns = append(columns, burnRateColumns()...)
+	}
+	if options.forecast {
+		columns = append(columns, forecastColumns...)
+	}
+	if baselines != nil {
+		columns = append(columns, anomalyColumns...)
+	}
+	if options.previousPeriod {
+		columns = append(columns, previousColumns...)
+	}
+	if rawResponseEnabled() {
+		columns = append(columns, rawResponseColumn)
+	}
+	return columns
+}
+
+// reportHeader returns the row columns followed by any derived columns and the policy action
+func reportHeader() []string {
+	header := rowColumns()
+	for _, col := range config.DerivedColumns {
+		header = append(header, col.Name)
+	}
+	if len(config.BudgetPolicy) > 0 {
+		header = append(header, policyActionColumn)
+	}
+	return header
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// convertExtensions are the file extensions of the converted reports by format
+var convertExtensions = map[string]string{"csv": ".csv", "json": ".jsonl", "gitlab": ".codequality.json", "junit": ".xml", "xlsx": ".xlsx", "parquet": ".parquet"}
+
+// runConvert re-renders a csv report in another output format, without calling the api
+func runConvert(_ context.Context, args []string) {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := fs.String("to", "", "output format: "+strings.Join(outputFormatNames(), ", "))
+	path := fs.String("o", "", "path of the converted report (default the report path with the format's extension e.g .xlsx)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s convert REPORT.csv -to FORMAT [-o PATH]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	reports := parseInterspersed(fs, args)
+	if len(reports) != 1 || *to == "" {
+		fs.Usage()
+		exit(2)
+	}
+	open, found := outputFormats[*to]
+	if !found {
+		fatalf("Unsupported format: %s, expected one of %s", *to, strings.Join(outputFormatNames(), ", "))
+	}
+	if *path == "" && !stdoutFormats[*to] {
+		*path = strings.TrimSuffix(reports[0], filepath.Ext(reports[0])) + convertExtensions[*to]
+		if options.encryptWith != "" {
+			*path = encryptedPath(*path, options.encryptWith)
+		}
+	}
+	if *path == reports[0] {
+		fatalf("Converted report path: %s is the report being converted, set -o", *path)
+	}
+
+	records, err := readReportCSV(reports[0])
+	if err != nil {
+		fatalf("Unable to read report: %s, err: %s", reports[0], err)
+	}
+	out, err := open(*path)
+	if err != nil {
+		fatalf("Unable to create file: %s, err: %s", *path, err)
+	}
+	for _, record := range records {
+		if err := out.writer.Write(record); err != nil {
+			fatalf("Unable to write to file: %s, err: %s", *path, err)
+		}
+	}
+	out.writer.Flush()
+	if err := out.close(); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", *path, err)
+	}
+	if out.path != "" {
+		log.Printf("Converted %d rows to %s written to: %s", len(records)-1, *to, out.path)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// credentialVariables are the environment variables a credential source may set
+var credentialVariables = []string{"DD_API_KEY", "DD_APP_KEY", "DD_SITE"}
+
+// runCredentialHelper runs the command and sets the DD_API_KEY, DD_APP_KEY and DD_SITE environment variables
+// from the KEY=VALUE lines it writes to stdout, so keys can come from any secret tooling
+func runCredentialHelper(command string) error {
+	cmd := shellCommand(command)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	set := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || !stringList(credentialVariables).contains(kv[0]) {
+			// the line may hold a secret, so it is not included in the error
+			return fmt.Errorf("unexpected output, expected %s=VALUE lines", strings.Join(credentialVariables, "|"))
+		}
+		if err := os.Setenv(kv[0], kv[1]); err != nil {
+			return err
+		}
+		set++
+	}
+	if set == 0 {
+		return fmt.Errorf("no credentials in output")
+	}
+	return scanner.Err()
+}
+
+// ssmParameter returns the decrypted value of an aws ssm parameter store parameter
+func ssmParameter(name string) (string, error) {
+	region, err := awsRegionFromEnv()
+	if err != nil {
+		return "", err
+	}
+	out, err := awsJSON("ssm", region, "1.1", "AmazonSSM.GetParameter", map[string]interface{}{
+		"Name":           name,
+		"WithDecryption": true,
+	})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Parameter struct {
+			Value string
+		}
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", err
+	}
+	return resp.Parameter.Value, nil
+}
+
+// loadSSMCredentials sets the datadog keys from the -api-key-ssm and -app-key-ssm parameters, if set
+func loadSSMCredentials() error {
+	for name, parameter := range map[string]string{"DD_API_KEY": options.apiKeySSM, "DD_APP_KEY": options.appKeySSM} {
+		if parameter == "" {
+			continue
+		}
+		value, err := ssmParameter(parameter)
+		if err != nil {
+			return fmt.Errorf("%s: %s", parameter, err)
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// sloWidgetTimeWindows are the timeframes slo widgets show, other timeframes use the dashboard time
+var sloWidgetTimeWindows = stringList{"7d", "30d", "90d"}
+
+// runDashboard creates or updates a datadog dashboard, found by title, with an slo widget for each slo matching
+// the tag query in a group per team, so new slos appear on the dashboard each time it runs
+func runDashboard(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+	title := fs.String("title", "SLO reliability", "title of the dashboard created or updated")
+	groupBy := fs.String("group-by", "team", "SLO tag key the widgets are grouped by")
+	dryRun := fs.Bool("dry-run", false, "print the dashboard json without creating or updating it")
+	parseFlags(fs, args)
+	if !*dryRun {
+		requireWritable("dashboard")
+	}
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	dashboard := sloDashboard(*title, *groupBy, slos)
+	if *dryRun {
+		content, err := json.MarshalIndent(dashboard, "", "  ")
+		if err != nil {
+			fatalf("Unable to generate dashboard, err: %s", err)
+		}
+		fmt.Println(string(content))
+		return
+	}
+
+	id, err := findDashboard(ctx, *title)
+	if err != nil {
+		fatalf("Unable to list dashboards, err: %s", err)
+	}
+	var saved struct {
+		ID string `json:"id"`
+	}
+	if id == "" {
+		err = datadogSend(ctx, http.MethodPost, "/api/v1/dashboard", nil, dashboard, &saved)
+	} else {
+		err = datadogSend(ctx, http.MethodPut, "/api/v1/dashboard/"+id, nil, dashboard, &saved)
+	}
+	if err != nil {
+		fatalf("Unable to save dashboard: %s, err: %s", *title, err)
+	}
+	action := "Created"
+	if id != "" {
+		action = "Updated"
+	}
+	log.Printf("%s dashboard with %d SLOs: https://app.%s/dashboard/%s", action, len(slos), datadogSite(), saved.ID)
+}
+
+// findDashboard returns the id of the dashboard with the title, empty when there is none
+func findDashboard(ctx context.Context, title string) (string, error) {
+	var resp struct {
+		Dashboards []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"dashboards"`
+	}
+	if err := datadogGet(ctx, "/api/v1/dashboard", nil, &resp); err != nil {
+		return "", err
+	}
+	for _, dashboard := range resp.Dashboards {
+		if dashboard.Title == title {
+			return dashboard.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// sloDashboard returns the dashboard definition, a group of slo widgets per value of the group by tag, sorted,
+// slos without the tag are grouped last
+func sloDashboard(title, groupBy string, slos []datadog.ServiceLevelObjective) map[string]interface{} {
+	groups := map[string][]interface{}{}
+	for _, slo := range slos {
+		group := sloTagValue(slo, groupBy)
+		groups[group] = append(groups[group], sloWidget(slo))
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, found := groups[""]; found {
+		names = append(names, "")
+	}
+
+	widgets := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		groupTitle := fmt.Sprintf("%s: %s", groupBy, name)
+		if name == "" {
+			groupTitle = fmt.Sprintf("No %s", groupBy)
+		}
+		widgets = append(widgets, map[string]interface{}{
+			"definition": map[string]interface{}{
+				"type":        "group",
+				"title":       groupTitle,
+				"layout_type": "ordered",
+				"widgets":     groups[name],
+			},
+		})
+	}
+	matching := "All SLOs"
+	if options.tagQuery != "" {
+		matching = fmt.Sprintf("SLOs tagged %s", options.tagQuery)
+	}
+	return map[string]interface{}{
+		"title":       title,
+		"description": fmt.Sprintf("%s grouped by %s. Managed by the slo report dashboard subcommand, manual changes are overwritten.", matching, groupBy),
+		"layout_type": "ordered",
+		"widgets":     widgets,
+	}
+}
+
+// sloWidget returns an slo widget showing the slo's timeframes, the dashboard time for other timeframes
+func sloWidget(slo datadog.ServiceLevelObjective) map[string]interface{} {
+	var windows []string
+	for _, threshold := range slo.Thresholds {
+		if tf := string(threshold.Timeframe); sloWidgetTimeWindows.contains(tf) {
+			windows = append(windows, tf)
+		}
+	}
+	if len(windows) == 0 {
+		windows = []string{"global_time"}
+	}
+	return map[string]interface{}{
+		"definition": map[string]interface{}{
+			"type":              "slo",
+			"title":             slo.GetName(),
+			"slo_id":            slo.GetId(),
+			"view_type":         "detail",
+			"view_mode":         "overall",
+			"time_windows":      windows,
+			"show_error_budget": true,
+		},
+	}
+}
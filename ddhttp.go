@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ddHTTPClient is used for datadog endpoints not covered by the api client version in use
+var ddHTTPClient = &http.Client{Timeout: 60 * time.Second, Transport: userAgentTransport{}}
+
+// datadogSite returns the site from DD_SITE, like the api client, defaulting to datadoghq.com
+func datadogSite() string {
+	if site := os.Getenv("DD_SITE"); site != "" {
+		return site
+	}
+	return "datadoghq.com"
+}
+
+// datadogURL returns the api url for the path, honoring DD_SITE like the api client
+func datadogURL(path string, query url.Values) string {
+	u := fmt.Sprintf("https://api.%s%s", datadogSite(), path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// datadogGet gets the api path and decodes the json response into out
+func datadogGet(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return datadogSend(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// datadogSend sends the request, with in encoded as the json body when set, and decodes the json response into out,
+// cancelled with the context
+func datadogSend(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		content, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(content)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, datadogURL(path, query), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("DD-API-KEY", os.Getenv("DD_API_KEY"))
+	req.Header.Set("DD-APPLICATION-KEY", os.Getenv("DD_APP_KEY"))
+	resp, err := ddHTTPClient.Do(req)
+	if err != nil {
+		return classifyAPIError(err, nil)
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return classifyAPIError(fmt.Errorf("%s %s: %s", path, resp.Status, strings.TrimSpace(string(content))), resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(content, out)
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// exportDefinition writes (encrypted if enabled) the full slo definition json to dir/<slo id>.json, returning its path
+func exportDefinition(dir string, slo datadog.ServiceLevelObjective) (string, error) {
+	content, err := json.MarshalIndent(slo, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return writeReportFile(filepath.Join(dir, slo.GetId()+".json"), content)
+}
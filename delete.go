@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// deletePlan is written by a delete dry run, only the slos in a plan can be deleted
+type deletePlan struct {
+	CreatedAt time.Time `json:"created_at"`
+	SLOIDs    []string  `json:"slo_ids"`
+}
+
+// runDelete deletes slos in two steps, -dry-run lists the slos matching the tag query and writes them to a plan,
+// deleting the slos of a plan then requires an interactive confirmation and takes a backup first
+func runDelete(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "list the slos matching the tag query and write them to the plan, required before deleting")
+	planPath := fs.String("plan", "slo_delete_plan.json", "path of the plan written by -dry-run and read when deleting")
+	backupPath := fs.String("backup", fmt.Sprintf("slo_backup_%s.tar.gz", runClock.Now().UTC().Format("20060102T150405Z")), "backup of the slos taken before deleting, directory or .tar.gz archive")
+	parseFlags(fs, args)
+	if !*dryRun {
+		requireWritable("delete")
+	}
+
+	if *dryRun {
+		slos, err := getSLODefinitions(ctx, options.limit, options.tagQuery)
+		if err != nil {
+			fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+		}
+		plan := deletePlan{CreatedAt: runClock.Now().UTC()}
+		for _, slo := range slos {
+			plan.SLOIDs = append(plan.SLOIDs, slo.GetId())
+		}
+		writeSLOList(slos)
+		content, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			fatalf("Unable to encode plan, err: %s", err)
+		}
+		if err := ioutil.WriteFile(*planPath, content, 0644); err != nil {
+			fatalf("Unable to write to file: %s, err: %s", *planPath, err)
+		}
+		log.Printf("Dry run - %d SLOs would be deleted, run `delete -plan %s` to delete them", len(slos), *planPath)
+		return
+	}
+
+	content, err := ioutil.ReadFile(*planPath)
+	if err != nil {
+		fatalf("Unable to read plan: %s (run delete -dry-run first), err: %s", *planPath, err)
+	}
+	var plan deletePlan
+	if err := json.Unmarshal(content, &plan); err != nil {
+		fatalf("Unable to parse plan: %s, err: %s", *planPath, err)
+	}
+	slos, err := getSLOsByID(ctx, plan.SLOIDs)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	if len(slos) == 0 {
+		log.Printf("Done - none of the SLOs in the plan exist")
+		return
+	}
+
+	writeSLOList(slos)
+	if !confirm(fmt.Sprintf("Delete these %d SLOs (planned %s)? Type yes to confirm: ", len(slos), plan.CreatedAt)) {
+		fatalf("Deletion not confirmed")
+	}
+	if err := writeBackup(*backupPath, slos); err != nil {
+		fatalf("Unable to write backup: %s, err: %s", *backupPath, err)
+	}
+	log.Printf("Backup of %d SLOs written to: %s", len(slos), *backupPath)
+
+	apiClient := newAPIClient()
+	for counter, slo := range slos {
+		log.Printf("(%d of %d) Deleting s: %s", counter+1, len(slos), slo.GetId())
+		if _, _, err := apiClient.ServiceLevelObjectivesApi.DeleteSLO(ctx, slo.GetId()); err != nil {
+			fatalf("Error when calling `ServiceLevelObjectivesApi.DeleteSLO` s: %s, err: %v\n", slo.GetId(), err)
+		}
+		if err := sleepContext(ctx, options.sleep); err != nil {
+			fatalf("Delete stopped after %d of %d SLOs, restore them with `restore -from %s`, err: %s", counter+1, len(slos), *backupPath, err)
+		}
+	}
+	log.Printf("Done - %d SLOs deleted, restore them with `restore -from %s`", len(slos), *backupPath)
+}
+
+// writeSLOList writes the name, id and tags of the slos to stdout as csv
+func writeSLOList(slos []datadog.ServiceLevelObjective) {
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	if err := writer.Write([]string{"name", "slo_id", "tags"}); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+	for _, slo := range slos {
+		if err := writer.Write([]string{slo.GetName(), slo.GetId(), strings.Join(slo.GetTags(), ",")}); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+	}
+}
+
+// confirm prompts on stderr and returns true when yes is answered on stdin
+func confirm(prompt string) bool {
+	fmt.Fprint(os.Stderr, prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// derivedColumn is an extra report column evaluated per row from an expression over the row's columns,
+// earlier derived columns and the derivedFields
+type derivedColumn struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+
+	expr *expression
+}
+
+// derivedFields are fields computed from the row that derived column expressions can use
+var derivedFields = map[string]func(lookup fieldLookup) (string, bool){
+	// window_minutes is the length of the evaluated window
+	"window_minutes": func(lookup fieldLookup) (string, bool) {
+		from, fromErr := lookupNumber(lookup, "from_ts")
+		to, toErr := lookupNumber(lookup, "to_ts")
+		if fromErr != nil || toErr != nil {
+			return "", true
+		}
+		return fmt.Sprintf("%f", (to-from)/60), true
+	},
+	// month_elapsed is the percentage of the calendar month of to_ts elapsed at to_ts
+	"month_elapsed": func(lookup fieldLookup) (string, bool) {
+		to, err := lookupNumber(lookup, "to_ts")
+		if err != nil {
+			return "", true
+		}
+		end := time.Unix(int64(to), 0).UTC()
+		start := startOfMonth(end)
+		return fmt.Sprintf("%f", end.Sub(start).Seconds()/start.AddDate(0, 1, 0).Sub(start).Seconds()*100), true
+	},
+	// error_budget_remaining is the percentage of the error budget left
+	"error_budget_remaining": func(lookup fieldLookup) (string, bool) {
+		consumed, err := lookupNumber(lookup, "error_budget_consumed")
+		if err != nil {
+			return "", true
+		}
+		return fmt.Sprintf("%f", 100-consumed), true
+	},
+}
+
+// parseDerivedColumns parses the column expressions, checking they only refer to known fields
+func parseDerivedColumns(cols []*derivedColumn) error {
+	header := rowColumns()
+	for _, col := range cols {
+		if col.Name == "" {
+			return fmt.Errorf("derived column without a name: %s", col.Expression)
+		}
+		expr, err := parseExpression(col.Expression)
+		if err != nil {
+			return fmt.Errorf("derived column %s: %s", col.Name, err)
+		}
+		if err := expr.checkFields(derivedLookup(header, header)); err != nil {
+			return fmt.Errorf("derived column %s: %s", col.Name, err)
+		}
+		col.expr = expr
+		header = append(header, col.Name)
+	}
+	return nil
+}
+
+// derivedWriter appends the derived columns to each record before writing it to the next writer
+type derivedWriter struct {
+	next    reportWriter
+	columns []*derivedColumn
+	header  []string
+}
+
+// Write appends the derived column names to the header, and their values to other records
+func (d *derivedWriter) Write(record []string) error {
+	if d.header == nil {
+		d.header = append([]string{}, record...)
+		for _, col := range d.columns {
+			d.header = append(d.header, col.Name)
+		}
+		return d.next.Write(d.header)
+	}
+
+	record = append([]string{}, record...)
+	for _, col := range d.columns {
+		value, err := col.expr.evalString(derivedLookup(d.header[:len(record)], record))
+		if err != nil {
+			log.Printf("Unable to evaluate derived column %s: %v, err: %s", col.Name, record, err)
+		}
+		record = append(record, value)
+	}
+	return d.next.Write(record)
+}
+
+// Flush flushes the next writer
+func (d *derivedWriter) Flush() {
+	d.next.Flush()
+}
+
+// derivedLookup looks up record values by header column name, falling back to the derivedFields
+func derivedLookup(header, record []string) fieldLookup {
+	lookup := recordLookup(header, record)
+	return func(name string) (string, bool) {
+		if v, found := lookup(name); found {
+			return v, true
+		}
+		if field, found := derivedFields[name]; found {
+			return field(lookup)
+		}
+		return "", false
+	}
+}
+
+// lookupNumber returns the numeric value of the field
+func lookupNumber(lookup fieldLookup, name string) (float64, error) {
+	v, _ := lookup(name)
+	return strconv.ParseFloat(v, 64)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// downtimeColumn holds the percentage of the window covered by downtimes of the slo's monitors, with -downtimes
+const downtimeColumn = "downtime_coverage"
+
+// timeInterval is a time range, to is exclusive
+type timeInterval struct {
+	from, to time.Time
+}
+
+// monitorDowntimes are the downtime intervals per monitor id, loaded once per run with -downtimes
+var monitorDowntimes map[int64][]timeInterval
+
+// loadMonitorDowntimes returns the downtime intervals per monitor id, downtimes by monitor tags apply to every
+// monitor with all the tags (* for all monitors), scoped downtimes are counted as covering the whole monitor
+func loadMonitorDowntimes(ctx context.Context, apiClient *datadog.APIClient) (map[int64][]timeInterval, error) {
+	downtimes, _, err := apiClient.DowntimesApi.ListDowntimes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var monitors []datadog.Monitor
+	byID := map[int64][]timeInterval{}
+	for _, downtime := range downtimes {
+		interval, ok := downtimeInterval(downtime)
+		if !ok {
+			continue
+		}
+		if id, ok := downtime.GetMonitorIdOk(); ok && id != nil {
+			byID[*id] = append(byID[*id], interval)
+			continue
+		}
+		if monitors == nil {
+			if monitors, err = listAllMonitors(ctx, apiClient, ""); err != nil {
+				return nil, err
+			}
+		}
+		for _, monitor := range monitors {
+			if hasAllTags(monitor.GetTags(), downtime.GetMonitorTags()) {
+				byID[monitor.GetId()] = append(byID[monitor.GetId()], interval)
+			}
+		}
+	}
+	return byID, nil
+}
+
+// downtimeInterval returns the downtime's time range, ending when canceled, open ended downtimes last until now,
+// only the current occurrence of recurring downtimes is listed
+func downtimeInterval(downtime datadog.Downtime) (timeInterval, bool) {
+	if downtime.GetDisabled() || downtime.Start == nil {
+		return timeInterval{}, false
+	}
+	interval := timeInterval{from: time.Unix(downtime.GetStart(), 0), to: runClock.Now()}
+	if end, ok := downtime.GetEndOk(); ok && end != nil {
+		interval.to = time.Unix(*end, 0)
+	}
+	if canceled, ok := downtime.GetCanceledOk(); ok && canceled != nil && time.Unix(*canceled, 0).Before(interval.to) {
+		interval.to = time.Unix(*canceled, 0)
+	}
+	return interval, interval.to.After(interval.from)
+}
+
+// hasAllTags returns true when tags contains every wanted tag, * matches any tags
+func hasAllTags(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if tag != "*" && !stringList(tags).contains(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// downtimeCoverage returns the percentage of the window covered by downtimes of the slo's monitors,
+// unset for slos without monitors (e.g metric slos) or rows without a window
+func downtimeCoverage(slo datadog.ServiceLevelObjective, from, to time.Time) *float64 {
+	covered, found := downtimeCovered(slo, from, to)
+	if !found {
+		return nil
+	}
+	coverage := float64(covered) / float64(to.Sub(from)) * 100
+	return &coverage
+}
+
+// downtimeCovered returns how long the window is covered by downtimes of the slo's monitors, false for slos without
+// monitors (e.g metric slos) or rows without a window
+func downtimeCovered(slo datadog.ServiceLevelObjective, from, to time.Time) (time.Duration, bool) {
+	if len(slo.GetMonitorIds()) == 0 || !to.After(from) {
+		return 0, false
+	}
+	var intervals []timeInterval
+	for _, id := range slo.GetMonitorIds() {
+		for _, interval := range monitorDowntimes[id] {
+			if interval.from.Before(from) {
+				interval.from = from
+			}
+			if interval.to.After(to) {
+				interval.to = to
+			}
+			if interval.to.After(interval.from) {
+				intervals = append(intervals, interval)
+			}
+		}
+	}
+
+	// sum the union of the overlapping intervals
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].from.Before(intervals[j].from) })
+	var covered time.Duration
+	var end time.Time
+	for _, interval := range intervals {
+		if interval.from.Before(end) {
+			interval.from = end
+		}
+		if interval.to.After(interval.from) {
+			covered += interval.to.Sub(interval.from)
+			end = interval.to
+		}
+	}
+	return covered, true
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestDowntimeCovered(t *testing.T) {
+	saved := monitorDowntimes
+	defer func() { monitorDowntimes = saved }()
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Hour)
+	at := func(hours float64) time.Time { return from.Add(time.Duration(hours * float64(time.Hour))) }
+	tests := []struct {
+		name      string
+		monitors  []int64
+		downtimes map[int64][]timeInterval
+		from, to  time.Time
+		covered   time.Duration
+		found     bool
+	}{
+		{"metric slo", nil, nil, from, to, 0, false},
+		{"empty window", []int64{1}, nil, from, from, 0, false},
+		{"no downtimes", []int64{1}, nil, from, to, 0, true},
+		{"single downtime", []int64{1}, map[int64][]timeInterval{1: {{at(1), at(3)}}}, from, to, 2 * time.Hour, true},
+		{"overlapping", []int64{1}, map[int64][]timeInterval{1: {{at(1), at(4)}, {at(2), at(5)}}}, from, to, 4 * time.Hour, true},
+		{"contained", []int64{1}, map[int64][]timeInterval{1: {{at(1), at(6)}, {at(2), at(3)}, {at(4), at(6)}}}, from, to, 5 * time.Hour, true},
+		{"adjacent", []int64{1}, map[int64][]timeInterval{1: {{at(1), at(2)}, {at(2), at(3)}}}, from, to, 2 * time.Hour, true},
+		{"unsorted", []int64{1}, map[int64][]timeInterval{1: {{at(6), at(7)}, {at(1), at(2)}}}, from, to, 2 * time.Hour, true},
+		{"overlapping across monitors", []int64{1, 2}, map[int64][]timeInterval{1: {{at(1), at(4)}}, 2: {{at(3), at(5)}}}, from, to, 4 * time.Hour, true},
+		{"other monitors ignored", []int64{1}, map[int64][]timeInterval{2: {{at(1), at(4)}}}, from, to, 0, true},
+		{"clipped to the window", []int64{1}, map[int64][]timeInterval{1: {{at(-5), at(1)}, {at(9), at(15)}}}, from, to, 2 * time.Hour, true},
+		{"before the window", []int64{1}, map[int64][]timeInterval{1: {{at(-5), at(-1)}}}, from, to, 0, true},
+		{"after the window", []int64{1}, map[int64][]timeInterval{1: {{at(10), at(12)}}}, from, to, 0, true},
+		{"whole window", []int64{1}, map[int64][]timeInterval{1: {{at(-1), at(11)}}}, from, to, 10 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitorDowntimes = tt.downtimes
+			slo := datadog.ServiceLevelObjective{}
+			if tt.monitors != nil {
+				slo.SetMonitorIds(tt.monitors)
+			}
+			covered, found := downtimeCovered(slo, tt.from, tt.to)
+			if covered != tt.covered || found != tt.found {
+				t.Errorf("downtimeCovered() = %s, %t, want %s, %t", covered, found, tt.covered, tt.found)
+			}
+		})
+	}
+}
+
+func TestDowntimeInterval(t *testing.T) {
+	now := time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)
+	unix := func(hours int) *int64 { v := now.Add(time.Duration(hours) * time.Hour).Unix(); return &v }
+	disabled := true
+	tests := []struct {
+		name     string
+		downtime datadog.Downtime
+		from, to *int64
+		ok       bool
+	}{
+		{"not started", datadog.Downtime{}, nil, nil, false},
+		{"disabled", datadog.Downtime{Start: unix(-2), Disabled: &disabled}, nil, nil, false},
+		{"open ended lasts until now", datadog.Downtime{Start: unix(-2)}, unix(-2), unix(0), true},
+		{"ended", datadog.Downtime{Start: unix(-5), End: *datadog.NewNullableInt64(unix(-3))}, unix(-5), unix(-3), true},
+		{"canceled early", datadog.Downtime{Start: unix(-5), End: *datadog.NewNullableInt64(unix(-1)), Canceled: *datadog.NewNullableInt64(unix(-4))}, unix(-5), unix(-4), true},
+		{"canceled before start", datadog.Downtime{Start: unix(-5), Canceled: *datadog.NewNullableInt64(unix(-6))}, nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeClock(t, now)
+			interval, ok := downtimeInterval(tt.downtime)
+			if ok != tt.ok {
+				t.Fatalf("downtimeInterval() ok = %t, want %t", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if interval.from.Unix() != *tt.from || interval.to.Unix() != *tt.to {
+				t.Errorf("downtimeInterval() = %s - %s, want %s - %s", interval.from, interval.to, time.Unix(*tt.from, 0), time.Unix(*tt.to, 0))
+			}
+		})
+	}
+}
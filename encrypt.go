@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// encryptedFile pipes written content through age or gpg into a file, so the plaintext report is never stored
+type encryptedFile struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	file  *os.File
+}
+
+// encryptedPath returns the path the report is written to when encrypting e.g /tmp/slo_report.csv.age
+func encryptedPath(path, tool string) string {
+	if tool == "gpg" {
+		return path + ".gpg"
+	}
+	return path + "." + tool
+}
+
+// createEncrypted creates the file at path, content written is encrypted for the recipients
+// with age or gpg (which must be installed)
+func createEncrypted(path, tool string, recipients []string) (*encryptedFile, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("encryption requires -recipients")
+	}
+	var args []string
+	switch tool {
+	case "age":
+		for _, recipient := range recipients {
+			args = append(args, "--recipient", recipient)
+		}
+	case "gpg":
+		args = []string{"--batch", "--yes", "--encrypt"}
+		for _, recipient := range recipients {
+			args = append(args, "--recipient", recipient)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption: %s, expected age or gpg", tool)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(tool, args...)
+	cmd.Stdout = file
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &encryptedFile{cmd: cmd, stdin: stdin, file: file}, nil
+}
+
+// Write writes plaintext to be encrypted
+func (e *encryptedFile) Write(p []byte) (int, error) {
+	return e.stdin.Write(p)
+}
+
+// Close finishes encryption and closes the file
+func (e *encryptedFile) Close() error {
+	e.stdin.Close()
+	err := e.cmd.Wait()
+	if closeErr := e.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %s", e.cmd.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"slos/schema"
+)
+
+// typedError is an error classified by its schema error type, written to the error_type column
+type typedError struct {
+	errType string
+	err     error
+}
+
+// Error returns the message of the wrapped error
+func (e *typedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *typedError) Unwrap() error {
+	return e.err
+}
+
+// withErrorType returns err classified as errType
+func withErrorType(errType string, err error) error {
+	return &typedError{errType: errType, err: err}
+}
+
+// classifyAPIError returns the api call error classified by the http status of the response, or the network error
+func classifyAPIError(err error, httpResp *http.Response) error {
+	if err == nil {
+		return nil
+	}
+	var typed *typedError
+	if errors.As(err, &typed) {
+		return err
+	}
+	if httpResp != nil {
+		switch {
+		case httpResp.StatusCode == http.StatusTooManyRequests:
+			return withErrorType(schema.ErrorRateLimited, err)
+		case httpResp.StatusCode == http.StatusNotFound:
+			return withErrorType(schema.ErrorNotFound, err)
+		case httpResp.StatusCode == http.StatusUnauthorized || httpResp.StatusCode == http.StatusForbidden:
+			return withErrorType(schema.ErrorUnauthorized, err)
+		case httpResp.StatusCode == http.StatusRequestTimeout || httpResp.StatusCode == http.StatusGatewayTimeout:
+			return withErrorType(schema.ErrorTimeout, err)
+		case httpResp.StatusCode >= 300:
+			return withErrorType(schema.ErrorAPI, err)
+		}
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return withErrorType(schema.ErrorTimeout, err)
+	}
+	if netErr != nil {
+		return withErrorType(schema.ErrorNetwork, err)
+	}
+	return withErrorType(schema.ErrorAPI, err)
+}
+
+// errorType returns the schema error type of err e.g RATE_LIMITED, OTHER for errors that are not classified
+func errorType(err error) string {
+	var typed *typedError
+	if errors.As(err, &typed) {
+		return typed.errType
+	}
+	return schema.ErrorOther
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"slos/schema"
+)
+
+// timeoutError is a network error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyAPIError(t *testing.T) {
+	status := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+	apiErr := errors.New("api error")
+	refused := &url.Error{Op: "Get", URL: "https://api.datadoghq.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	tests := []struct {
+		name     string
+		err      error
+		resp     *http.Response
+		wantType string
+	}{
+		{"unauthorized", apiErr, status(http.StatusUnauthorized), schema.ErrorUnauthorized},
+		{"forbidden", apiErr, status(http.StatusForbidden), schema.ErrorUnauthorized},
+		{"not found", apiErr, status(http.StatusNotFound), schema.ErrorNotFound},
+		{"request timeout", apiErr, status(http.StatusRequestTimeout), schema.ErrorTimeout},
+		{"rate limited", apiErr, status(http.StatusTooManyRequests), schema.ErrorRateLimited},
+		{"gateway timeout", apiErr, status(http.StatusGatewayTimeout), schema.ErrorTimeout},
+		{"server error", apiErr, status(http.StatusInternalServerError), schema.ErrorAPI},
+		{"bad request", apiErr, status(http.StatusBadRequest), schema.ErrorAPI},
+		{"error with a successful response e.g decoding", apiErr, status(http.StatusOK), schema.ErrorAPI},
+		{"network", refused, nil, schema.ErrorNetwork},
+		{"dns", &net.DNSError{Err: "no such host", Name: "api.datadoghq.com"}, nil, schema.ErrorNetwork},
+		{"network timeout", &url.Error{Op: "Get", URL: "https://api.datadoghq.com", Err: timeoutError{}}, nil, schema.ErrorTimeout},
+		{"deadline exceeded", fmt.Errorf("get history: %w", context.DeadlineExceeded), nil, schema.ErrorTimeout},
+		{"other without response", apiErr, nil, schema.ErrorAPI},
+		{"already classified", withErrorType(schema.ErrorNoData, apiErr), status(http.StatusNotFound), schema.ErrorNoData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyAPIError(tt.err, tt.resp)
+			if got := errorType(err); got != tt.wantType {
+				t.Errorf("errorType(classifyAPIError()) = %s, want %s", got, tt.wantType)
+			}
+			if err.Error() != tt.err.Error() {
+				t.Errorf("classifyAPIError() = %q, want the message %q", err, tt.err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("classifyAPIError() does not wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"unclassified", errors.New("boom"), schema.ErrorOther},
+		{"classified", withErrorType(schema.ErrorNotFound, errors.New("gone")), schema.ErrorNotFound},
+		{"wrapped classified", fmt.Errorf("chunk: %w", withErrorType(schema.ErrorRateLimited, errors.New("429"))), schema.ErrorRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorType(tt.err); got != tt.want {
+				t.Errorf("errorType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if err := classifyAPIError(nil, &http.Response{StatusCode: http.StatusNotFound}); err != nil {
+		t.Errorf("classifyAPIError(nil) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import "github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+
+// eventColumns hold the good (numerator) and total (denominator) event counts of metric slos, when enabled
+var eventColumns = []string{"good_events", "total_events"}
+
+// withEventCounts sets the good and total event counts of the window from the history series,
+// only metric slos have one
+func withEventCounts(row reportRow, history datadog.SLOHistoryResponse) reportRow {
+	series, ok := history.Data.GetSeriesOk()
+	if !ok {
+		return row
+	}
+	good, total := series.Numerator.Sum, series.Denominator.Sum
+	row.goodEvents, row.totalEvents = &good, &total
+	return row
+}
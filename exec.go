@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// shellCommand returns the command running the command line with the platform shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// execAfterData is the data of the -exec-after command template e.g {{.Path}} {{.ManifestPath}} {{.Rows}}
+type execAfterData struct {
+	manifest
+	Path         string
+	ManifestPath string
+}
+
+// runExecAfter runs the -exec-after command template once the report is complete
+func runExecAfter(m manifest, manifestFile string) error {
+	command, err := renderTemplate(options.execAfter, execAfterData{manifest: m, Path: m.Report, ManifestPath: manifestFile})
+	if err != nil {
+		return err
+	}
+	cmd := shellCommand(command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// execWriter writes each record as a json object (keyed by the header columns) line to the stdin
+// of a command started for the run, before writing it to the next writer
+type execWriter struct {
+	next   reportWriter
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	header []string
+}
+
+// newExecWriter starts the command, writing records to its stdin and to next
+func newExecWriter(next reportWriter, command string) (*execWriter, error) {
+	cmd := shellCommand(command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &execWriter{next: next, cmd: cmd, stdin: stdin}, nil
+}
+
+// Write writes the record as a json line to the command, the header is only written to next
+func (w *execWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		return w.next.Write(record)
+	}
+	content, err := json.Marshal(recordMap(w.header, record))
+	if err != nil {
+		return err
+	}
+	if _, err := w.stdin.Write(append(content, '\n')); err != nil {
+		return err
+	}
+	return w.next.Write(record)
+}
+
+// Flush flushes the next writer
+func (w *execWriter) Flush() {
+	w.next.Flush()
+}
+
+// close closes the command's stdin and waits for it to exit
+func (w *execWriter) close() error {
+	if err := w.stdin.Close(); err != nil {
+		return err
+	}
+	return w.cmd.Wait()
+}
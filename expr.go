@@ -0,0 +1,431 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// expression is a parsed expression over report fields e.g error_budget_consumed > 80 && timeframe == "30d"
+//
+// supported are numbers, "strings", true/false, field names, parentheses, arithmetic (+ - * /),
+// comparisons (== != < <= > >=) and boolean operators (&& || !). Fields are compared as numbers
+// when both sides are numeric, ordering comparisons against non-numeric values are false.
+type expression struct {
+	source string
+	fields []string
+	eval   evalFunc
+}
+
+// fieldLookup returns the value of a field, false when there is no such field
+type fieldLookup func(name string) (string, bool)
+
+// evalFunc evaluates a (sub) expression to a float64, string or bool
+type evalFunc func(lookup fieldLookup) (interface{}, error)
+
+// parseExpression parses source, returning an error describing the first syntax error
+func parseExpression(source string) (*expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	eval, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in expression: %s", p.tokens[p.pos].text, source)
+	}
+	return &expression{source: source, fields: p.fields, eval: eval}, nil
+}
+
+// evalBool evaluates the expression as a condition
+func (e *expression) evalBool(lookup fieldLookup) (bool, error) {
+	v, err := e.eval(lookup)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+// evalString evaluates the expression and formats the result as a report value
+func (e *expression) evalString(lookup fieldLookup) (string, error) {
+	v, err := e.eval(lookup)
+	if err != nil {
+		return "", err
+	}
+	if f, ok := v.(float64); ok {
+		return fmt.Sprintf("%f", f), nil
+	}
+	return fmt.Sprintf("%v", v), nil
+}
+
+// checkFields returns an error for the first field the expression uses that is not known
+func (e *expression) checkFields(lookup fieldLookup) error {
+	for _, field := range e.fields {
+		if _, found := lookup(field); !found {
+			return fmt.Errorf("unknown field %s in expression: %s", field, e.source)
+		}
+	}
+	return nil
+}
+
+// token kinds
+const (
+	tokenNumber = iota
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+// operators ordered so two character operators are matched first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+// tokenize splits source into tokens
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i])})
+		case r == '"' || r == '\'':
+			start := i + 1
+			for i = start; i < len(runes) && runes[i] != r; i++ {
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string in expression: %s", source)
+			}
+			tokens = append(tokens, token{tokenString, string(runes[start:i])})
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, string(runes[start:i])})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, token{tokenOperator, op})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q in expression: %s", r, source)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser is a recursive descent parser, lowest precedence first
+type exprParser struct {
+	tokens []token
+	pos    int
+	fields []string
+}
+
+// accept consumes the next token if it is one of the operators
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseOr() (evalFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(lookup fieldLookup) (interface{}, error) {
+			lv, err := l(lookup)
+			if err != nil || truthy(lv) {
+				return true, err
+			}
+			rv, err := right(lookup)
+			return truthy(rv), err
+		}
+	}
+}
+
+func (p *exprParser) parseAnd() (evalFunc, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(lookup fieldLookup) (interface{}, error) {
+			lv, err := l(lookup)
+			if err != nil || !truthy(lv) {
+				return false, err
+			}
+			rv, err := right(lookup)
+			return truthy(rv), err
+		}
+	}
+}
+
+func (p *exprParser) parseNot() (evalFunc, error) {
+	if _, ok := p.accept("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(lookup fieldLookup) (interface{}, error) {
+			v, err := operand(lookup)
+			return !truthy(v), err
+		}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (evalFunc, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return func(lookup fieldLookup) (interface{}, error) {
+		lv, err := left(lookup)
+		if err != nil {
+			return nil, err
+		}
+		rv, err := right(lookup)
+		if err != nil {
+			return nil, err
+		}
+		return compare(op, lv, rv), nil
+	}, nil
+}
+
+func (p *exprParser) parseSum() (evalFunc, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmetic(op, left, right)
+	}
+}
+
+func (p *exprParser) parseProduct() (evalFunc, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmetic(op, left, right)
+	}
+}
+
+func (p *exprParser) parseUnary() (evalFunc, error) {
+	if _, ok := p.accept("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		zero := func(fieldLookup) (interface{}, error) { return 0.0, nil }
+		return arithmetic("-", zero, operand), nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (evalFunc, error) {
+	if _, ok := p.accept("("); ok {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	}
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+	switch tok.kind {
+	case tokenNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", tok.text)
+		}
+		return func(fieldLookup) (interface{}, error) { return f, nil }, nil
+	case tokenString:
+		return func(fieldLookup) (interface{}, error) { return tok.text, nil }, nil
+	case tokenIdent:
+		if tok.text == "true" || tok.text == "false" {
+			b := tok.text == "true"
+			return func(fieldLookup) (interface{}, error) { return b, nil }, nil
+		}
+		p.fields = append(p.fields, tok.text)
+		return func(lookup fieldLookup) (interface{}, error) {
+			v, found := lookup(tok.text)
+			if !found {
+				return nil, fmt.Errorf("unknown field %s", tok.text)
+			}
+			return v, nil
+		}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
+
+// arithmetic returns an evalFunc applying op to numeric operands
+func arithmetic(op string, left, right evalFunc) evalFunc {
+	return func(lookup fieldLookup) (interface{}, error) {
+		lv, err := left(lookup)
+		if err != nil {
+			return nil, err
+		}
+		rv, err := right(lookup)
+		if err != nil {
+			return nil, err
+		}
+		l, lok := asNumber(lv)
+		r, rok := asNumber(rv)
+		if !lok || !rok {
+			return nil, fmt.Errorf("non-numeric operand for %s: %v %s %v", op, lv, op, rv)
+		}
+		switch op {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		}
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	}
+}
+
+// compare applies a comparison operator, numerically when both values are numbers
+func compare(op string, lv, rv interface{}) bool {
+	l, lok := asNumber(lv)
+	r, rok := asNumber(rv)
+	if lok && rok {
+		switch op {
+		case "==":
+			return l == r
+		case "!=":
+			return l != r
+		case "<":
+			return l < r
+		case "<=":
+			return l <= r
+		case ">":
+			return l > r
+		case ">=":
+			return l >= r
+		}
+	}
+
+	ls, rs := fmt.Sprintf("%v", lv), fmt.Sprintf("%v", rv)
+	switch op {
+	case "==":
+		return ls == rs
+	case "!=":
+		return ls != rs
+	}
+	return false
+}
+
+// asNumber converts numbers and numeric strings to float64
+func asNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// truthy returns the boolean value of v, non-zero numbers and non-empty strings are true except "false" and numeric
+// zero strings, so boolean columns e.g regressed hold "true" or "false" and can be used as conditions
+func truthy(v interface{}) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case float64:
+		return b != 0
+	case string:
+		if f, ok := asNumber(b); ok {
+			return f != 0
+		}
+		s := strings.TrimSpace(b)
+		return s != "" && !strings.EqualFold(s, "false")
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+func TestParseExpression(t *testing.T) {
+	fields := map[string]string{
+		"error_budget_consumed": "85.500000",
+		"timeframe":             "30d",
+		"target":                "99.900000",
+		"overall_status":        "",
+	}
+	lookup := func(name string) (string, bool) {
+		v, found := fields[name]
+		return v, found
+	}
+	tests := []struct {
+		source string
+		want   string
+		err    bool
+	}{
+		{source: `error_budget_consumed > 80 && timeframe == "30d"`, want: "true"},
+		{source: `error_budget_consumed > 90 || timeframe == "7d"`, want: "false"},
+		{source: `!(timeframe == "7d")`, want: "true"},
+		{source: `100 - target`, want: "0.100000"},
+		{source: `(100 - error_budget_consumed) * 2`, want: "29.000000"},
+		{source: `1 + 2 * 3`, want: "7.000000"},
+		{source: `-target + 100`, want: "0.100000"},
+		{source: `error_budget_consumed / 0`, err: true},
+		{source: `timeframe < 90`, want: "false"},
+		{source: `overall_status == ""`, want: "true"},
+		{source: `timeframe + 1`, err: true},
+		{source: `unknown_column > 1`, err: true},
+		{source: `(target > 1`, err: true},
+		{source: `target >`, err: true},
+		{source: `target 1`, err: true},
+		{source: `"unterminated`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			expr, err := parseExpression(tt.source)
+			if err == nil {
+				var got string
+				got, err = expr.evalString(lookup)
+				if err == nil && got != tt.want {
+					t.Errorf("evalString() = %s, want %s", got, tt.want)
+				}
+			}
+			if (err != nil) != tt.err {
+				t.Errorf("err = %v, want error %t", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestExpressionConditions(t *testing.T) {
+	fields := map[string]string{
+		"regressed":          "false",
+		"masked_by_mute_now": "true",
+		"sla_risk":           "FALSE",
+		"muted_monitors_now": "0",
+		"good_events":        "0.000000",
+		"total_events":       "12.000000",
+		"group":              "",
+		"name":               "checkout",
+	}
+	lookup := func(name string) (string, bool) {
+		v, found := fields[name]
+		return v, found
+	}
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"regressed", false},
+		{"!regressed", true},
+		{"masked_by_mute_now", true},
+		{"!masked_by_mute_now", false},
+		{"sla_risk", false},
+		{"muted_monitors_now", false},
+		{"good_events", false},
+		{"total_events", true},
+		{"group", false},
+		{"name", true},
+		{"regressed || masked_by_mute_now", true},
+		{"regressed && masked_by_mute_now", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			expr, err := parseExpression(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := expr.evalBool(lookup)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("evalBool() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
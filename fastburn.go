@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// burnWindow is a short window with the burn rate threshold of multi window burn rate alerting
+type burnWindow struct {
+	name      string
+	duration  time.Duration
+	threshold float64
+}
+
+// burnWindows are the short windows evaluated with -fast-burn, with the thresholds of the google sre workbook
+// i.e 2% of a 30d budget in 1h, 5% in 6h and 10% in 24h
+var burnWindows = []burnWindow{
+	{"1h", time.Hour, 14.4},
+	{"6h", 6 * time.Hour, 6},
+	{"24h", OneDay, 3},
+}
+
+// burnRateColumns returns the burn rate column of each short window followed by fast_burn
+func burnRateColumns() []string {
+	var columns []string
+	for _, w := range burnWindows {
+		columns = append(columns, "burn_rate_"+w.name)
+	}
+	return append(columns, "fast_burn")
+}
+
+// shortWindowSLIs are the slis of the slos being reported over each burn window, by slo id
+var shortWindowSLIs = map[string][]*float64{}
+
+// shortWindowErrs are the errors of the burn windows whose history can't be read, by slo id
+var shortWindowErrs = map[string][]error{}
+
+// firstError returns the first error that is set, if any
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadShortWindowSLIs gets the sli of the slo over each burn window, and the error of each window whose history
+// can't be read, the sli doesn't depend on the target so the first threshold is used for the history calls
+func loadShortWindowSLIs(ctx context.Context, apiClient *datadog.APIClient, slo datadog.ServiceLevelObjective, now time.Time) ([]*float64, []error) {
+	slis, errs := make([]*float64, len(burnWindows)), make([]error, len(burnWindows))
+	if len(slo.Thresholds) == 0 {
+		return slis, errs
+	}
+	for i, w := range burnWindows {
+		history, err := getSLOHistory(ctx, apiClient, slo, slo.Thresholds[0], now.Add(-w.duration), now)
+		summary.recordHistoryCall(err)
+		if err != nil {
+			log.Printf("Unable to get slo history s: %s, tf: %s, err: %s", slo.GetId(), w.name, err)
+			errs[i] = err
+			continue
+		}
+		slis[i], _ = history.Data.Overall.GetSliValueOk()
+	}
+	return slis, errs
+}
+
+// burnRates returns the burn rate of each burn window against the target, the error rate over the allowed
+// error rate, and whether any exceeds its threshold
+func burnRates(slis []*float64, target float64) ([]*float64, bool) {
+	rates := make([]*float64, len(slis))
+	fast := false
+	for i, sli := range slis {
+		if sli == nil || target >= 100 {
+			continue
+		}
+		rate := (100 - *sli) / (100 - target)
+		rates[i] = &rate
+		if rate > burnWindows[i].threshold {
+			fast = true
+		}
+	}
+	return rates, fast
+}
+
+// burnRateValues returns the row's burn rate column values, fast_burn is left empty when no window burns fast but
+// the history of one can't be read
+func (r reportRow) burnRateValues() []string {
+	slis, found := shortWindowSLIs[r.slo.GetId()]
+	if !found {
+		return make([]string, len(burnWindows)+1)
+	}
+	rates, fast := burnRates(slis, r.threshold.GetTarget())
+	values := make([]string, 0, len(rates)+1)
+	for _, rate := range rates {
+		values = append(values, formatOptionalFloat(rate))
+	}
+	if !fast && firstError(shortWindowErrs[r.slo.GetId()]) != nil {
+		return append(values, "")
+	}
+	return append(values, fmt.Sprintf("%t", fast))
+}
+
+// activeIncident is an slo burning error budget fast
+type activeIncident struct {
+	slo       datadog.ServiceLevelObjective
+	target    float64
+	burnRates []*float64
+}
+
+// incidentsPath returns the active incidents path for a report e.g /tmp/slo_report.incidents.csv for /tmp/slo_report.csv
+func incidentsPath(reportPath string) string {
+	plain := strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ".incidents.csv"
+	if options.encryptWith != "" {
+		return encryptedPath(plain, options.encryptWith)
+	}
+	return plain
+}
+
+// writeIncidents writes (encrypted if enabled) the active incidents with their burn rates
+func writeIncidents(path string, incidents []activeIncident) error {
+	file, err := createReportFile(path)
+	if err != nil {
+		return err
+	}
+	writer, err := newCSVWriter(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	cols := append([]string{"name", "slo_id", "target"}, burnRateColumns()[:len(burnWindows)]...)
+	if err := writer.Write(cols); err != nil {
+		return err
+	}
+	for _, incident := range incidents {
+		data := []string{incident.slo.GetName(), incident.slo.GetId(), fmt.Sprintf("%f", incident.target)}
+		for _, rate := range incident.burnRates {
+			data = append(data, formatOptionalFloat(rate))
+		}
+		if err := writer.Write(data); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestBurnRates(t *testing.T) {
+	sli := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name   string
+		slis   []*float64
+		target float64
+		want   []string
+		fast   bool
+	}{
+		{"within budget", []*float64{sli(99.9), sli(99.9), sli(99.9)}, 99, []string{"0.100000", "0.100000", "0.100000"}, false},
+		{"1h above 14.4", []*float64{sli(80), sli(99.9), sli(99.9)}, 99, []string{"20.000000", "0.100000", "0.100000"}, true},
+		{"24h above 3", []*float64{sli(99), sli(99), sli(96)}, 99, []string{"1.000000", "1.000000", "4.000000"}, true},
+		{"no data", []*float64{nil, sli(99.5), nil}, 99, []string{"", "0.500000", ""}, false},
+		{"100% target", []*float64{sli(99.9), sli(99.9), sli(99.9)}, 100, []string{"", "", ""}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, fast := burnRates(tt.slis, tt.target)
+			for i, rate := range rates {
+				if got := formatOptionalFloat(rate); got != tt.want[i] {
+					t.Errorf("burn rate %s = %s, want %s", burnWindows[i].name, got, tt.want[i])
+				}
+			}
+			if fast != tt.fast {
+				t.Errorf("fast = %t, want %t", fast, tt.fast)
+			}
+		})
+	}
+}
+
+func TestBurnRateValues(t *testing.T) {
+	savedSLIs, savedErrs := shortWindowSLIs, shortWindowErrs
+	defer func() { shortWindowSLIs, shortWindowErrs = savedSLIs, savedErrs }()
+	sli := func(v float64) *float64 { return &v }
+	unavailable := errors.New("api error: 500 Internal Server Error")
+	tests := []struct {
+		name string
+		slis []*float64
+		errs []error
+		want string
+	}{
+		{"not burning fast", []*float64{sli(99.9), sli(99.9), sli(99.9)}, make([]error, 3), "false"},
+		{"burning fast", []*float64{sli(80), sli(99.9), sli(99.9)}, make([]error, 3), "true"},
+		{"window unavailable", []*float64{nil, sli(99.9), sli(99.9)}, []error{unavailable, nil, nil}, ""},
+		{"burning fast with a window unavailable", []*float64{nil, sli(90), sli(99.9)}, []error{unavailable, nil, nil}, "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slo := datadog.ServiceLevelObjective{Id: datadog.PtrString("abc123")}
+			shortWindowSLIs = map[string][]*float64{"abc123": tt.slis}
+			shortWindowErrs = map[string][]error{"abc123": tt.errs}
+			values := reportRow{slo: slo, threshold: datadog.SLOThreshold{Target: 99}}.burnRateValues()
+			if got := values[len(values)-1]; got != tt.want {
+				t.Errorf("fast_burn = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
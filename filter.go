@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log"
+	"strings"
+)
+
+// rowFilter is the parsed -filter expression, nil when all rows are written
+var rowFilter *expression
+
+// parseRowFilter parses a -filter expression, checking it only refers to report (or derived) columns
+func parseRowFilter(source string) (*expression, error) {
+	filter, err := parseExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	header := reportHeader()
+	if err := filter.checkFields(recordLookup(header, header)); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// filterWriter writes the header and the records matching the filter to the next writer
+type filterWriter struct {
+	next   reportWriter
+	filter *expression
+	header []string
+}
+
+// Write writes the record if it is the header or matches the filter
+func (f *filterWriter) Write(record []string) error {
+	if f.header == nil {
+		f.header = record
+		return f.next.Write(record)
+	}
+	match, err := f.filter.evalBool(recordLookup(f.header, record))
+	if err != nil {
+		log.Printf("Unable to evaluate filter, row skipped: %v, err: %s", record, err)
+		return nil
+	}
+	if !match {
+		return nil
+	}
+	return f.next.Write(record)
+}
+
+// Flush flushes the next writer
+func (f *filterWriter) Flush() {
+	f.next.Flush()
+}
+
+// recordLookup returns a lookup of record values by header column name, columns with a
+// parenthesised suffix e.g "error (only if applicable)" can also be looked up without it
+func recordLookup(header, record []string) fieldLookup {
+	return func(name string) (string, bool) {
+		for i, col := range header {
+			if (col == name || strings.HasPrefix(col, name+" (")) && i < len(record) {
+				return record[i], true
+			}
+		}
+		return "", false
+	}
+}
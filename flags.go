@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"strings"
+)
+
+// stringList is a flag.Value for comma separated values e.g 30d,90d
+type stringList []string
+
+// String returns the comma separated values
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set appends the comma separated values
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// contains returns true when v is in the list
+func (l stringList) contains(v string) bool {
+	for _, item := range l {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// parseInterspersed parses the flag set's flags wherever they are in args e.g merge a.csv b.csv -o combined.csv,
+// returning the other arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var rest []string
+	for parseFlags(fs, args); fs.NArg() > 0; parseFlags(fs, args) {
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	return rest
+}
+
+// parseFlags parses the subcommand flags of a flag.ContinueOnError flag set, exiting on errors (or -h) like
+// flag.ExitOnError once the run lock is released
+func parseFlags(fs *flag.FlagSet, args []string) {
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		exit(0)
+	}
+	if err != nil {
+		exit(2)
+	}
+}
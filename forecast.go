@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// forecastColumns hold the projected end of month sli with its 95% confidence band and status, with -forecast
+var forecastColumns = []string{"forecast_sli", "forecast_low", "forecast_high", "forecast_status"}
+
+// StatusAtRisk is the forecast status of slos projected to meet their target with a confidence band below it
+const StatusAtRisk = "AT_RISK"
+
+// sloForecast is the projected end of month sli of an slo
+type sloForecast struct {
+	sli, low, high float64
+}
+
+// monthForecasts are the forecasts of the slo being reported, by slo id
+var monthForecasts = map[string]*sloForecast{}
+
+// forecastMonth projects the slo's sli at the end of the current calendar month from the month to date
+// good and total events of metric slos, nil for monitor slos or without enough days of data
+func forecastMonth(ctx context.Context, apiClient *datadog.APIClient, slo datadog.ServiceLevelObjective, now time.Time) *sloForecast {
+	from := startOfMonth(now)
+	days := int(from.AddDate(0, 1, 0).Sub(from) / OneDay)
+	if slo.GetType() != datadog.SLOTYPE_METRIC || len(slo.Thresholds) == 0 {
+		return nil
+	}
+	history, err := getSLOHistory(ctx, apiClient, slo, slo.Thresholds[0], from, now)
+	summary.recordHistoryCall(err)
+	if err != nil {
+		log.Printf("Unable to get month to date slo history s: %s, err: %s", slo.GetId(), err)
+		return nil
+	}
+	series, ok := history.Data.GetSeriesOk()
+	if !ok {
+		return nil
+	}
+
+	// daily good and total events of the days elapsed
+	good, total := dailyEvents(series, from, int(now.Sub(from)/OneDay)+1)
+	return projectSLI(good, total, days)
+}
+
+// dailyEvents returns the good and total events of the series per utc day, for the days from from
+func dailyEvents(series *datadog.SLOHistoryMetrics, from time.Time, days int) ([]float64, []float64) {
+	good, total := make([]float64, days), make([]float64, days)
+	for i, ts := range series.Times {
+		if i >= len(series.Numerator.Values) || i >= len(series.Denominator.Values) {
+			break
+		}
+		t := time.Unix(int64(ts), 0)
+		if ts > 1e11 {
+			// millisecond timestamps
+			t = time.Unix(0, int64(ts)*int64(time.Millisecond))
+		}
+		day := int(t.Sub(from) / OneDay)
+		if day < 0 || day >= days {
+			continue
+		}
+		good[day] += series.Numerator.Values[i]
+		total[day] += series.Denominator.Values[i]
+	}
+	return good, total
+}
+
+// projectSLI fits a linear trend over the daily slis and projects the sli after days, the remaining days
+// have the average daily events, the band is the 95% interval of their mean sli from the trend residuals
+func projectSLI(good, total []float64, days int) *sloForecast {
+	var xs, ys []float64
+	var goodSum, totalSum float64
+	for day := range total {
+		goodSum += good[day]
+		totalSum += total[day]
+		if total[day] > 0 {
+			xs = append(xs, float64(day))
+			ys = append(ys, good[day]/total[day]*100)
+		}
+	}
+	if len(xs) < 2 || totalSum == 0 {
+		return nil
+	}
+
+	// least squares fit of sli = a + b * day
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	b := 0.0
+	if d := n*sxx - sx*sx; d != 0 {
+		b = (n*sxy - sx*sy) / d
+	}
+	a := (sy - b*sx) / n
+	var residuals float64
+	for i := range xs {
+		r := ys[i] - (a + b*xs[i])
+		residuals += r * r
+	}
+	stddev := math.Sqrt(residuals / n)
+
+	remaining := days - len(total)
+	if remaining <= 0 {
+		sli := goodSum / totalSum * 100
+		return &sloForecast{sli: sli, low: sli, high: sli}
+	}
+	dailyTotal := totalSum / float64(len(total))
+	var futureSLI float64
+	for day := len(total); day < days; day++ {
+		futureSLI += math.Min(100, math.Max(0, a+b*float64(day)))
+	}
+	futureSLI /= float64(remaining)
+	futureTotal := dailyTotal * float64(remaining)
+
+	sli := (goodSum + futureSLI/100*futureTotal) / (totalSum + futureTotal) * 100
+	// the future days' share of the month sli times the 95% interval of their mean sli
+	band := futureTotal / (totalSum + futureTotal) * 1.96 * stddev / math.Sqrt(float64(remaining))
+	return &sloForecast{sli: sli, low: math.Max(0, sli-band), high: math.Min(100, sli+band)}
+}
+
+// forecastValues returns the row's forecast column values, the status is against the row's target
+func (r reportRow) forecastValues() []string {
+	forecast := monthForecasts[r.slo.GetId()]
+	if forecast == nil {
+		return make([]string, len(forecastColumns))
+	}
+	status := StatusOK
+	switch target := r.threshold.GetTarget(); {
+	case forecast.sli < target:
+		status = StatusBreached
+	case forecast.low < target:
+		status = StatusAtRisk
+	}
+	return []string{
+		formatOptionalFloat(&forecast.sli),
+		formatOptionalFloat(&forecast.low),
+		formatOptionalFloat(&forecast.high),
+		status,
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numericColumns are the report columns holding decimal numbers, derived columns are numeric too
+var numericColumns = []string{"target", "warning", "overall_status", "error_budget_consumed", groupTargetColumn, "good_events", "total_events", downtimeColumn, "downtime_hours", "sla_target", "burn_rate_1h", "burn_rate_6h", "burn_rate_24h", "forecast_sli", "forecast_low", "forecast_high", "budget_consumed_mean", "budget_consumed_stddev", "previous_sli", "sli_change", "budget_consumed_change"}
+
+// dateColumns are the report columns holding times, as formatted by time.Time.String
+var dateColumns = []string{"from (utc)", "to (utc)"}
+
+// excelDateFormat is a date layout spreadsheets parse as a date time
+const excelDateFormat = "2006-01-02 15:04:05"
+
+// utf8BOM marks the csv as utf-8 for spreadsheets, so non-ascii slo names are not mangled
+const utf8BOM = "\xef\xbb\xbf"
+
+// csvWriter is a csv writer for report files, escaping formulas if enabled
+type csvWriter struct {
+	*csv.Writer
+	// format is the format writer before it if any, localized numbers e.g -1,5 in its numeric columns are not escaped
+	format *formatWriter
+}
+
+// newCSVWriter returns a csv writer for a report file, starting it with a utf-8 bom and using crlf line endings if enabled
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	if options.bom {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = options.crlf
+	return &csvWriter{Writer: writer}, nil
+}
+
+// Write writes the record, escaping cells spreadsheets would evaluate as formulas when -sanitize-formulas is set
+func (w *csvWriter) Write(record []string) error {
+	if !options.sanitizeFormulas {
+		return w.Writer.Write(record)
+	}
+	sanitized := make([]string, len(record))
+	for i, value := range record {
+		if w.format != nil && w.format.numeric[i] && w.format.isLocalizedNumber(value) {
+			sanitized[i] = value
+			continue
+		}
+		sanitized[i] = sanitizeFormula(value)
+	}
+	return w.Writer.Write(sanitized)
+}
+
+// sanitizeFormula prefixes values starting with a formula character (= + - @ tab or carriage return) with a quote,
+// so spreadsheets show them as text, numbers e.g -1.5 are left as is
+func sanitizeFormula(value string) string {
+	if value == "" || !strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// timeStringLayout is the layout of time.Time.String e.g 2023-05-01 00:00:00 +0000 UTC
+const timeStringLayout = "2006-01-02 15:04:05 -0700 MST"
+
+// formatWriter renames columns and formats records for people opening the output (e.g in a spreadsheet) before writing them to the next
+// writer, so the writers before it keep working with machine readable values
+type formatWriter struct {
+	next       reportWriter
+	decimal    string
+	dateLayout string
+	numeric    map[int]bool
+	dates      map[int]bool
+	rename     bool
+}
+
+// formatEnabled returns true when any output formatting option is set
+func formatEnabled() bool {
+	return options.decimalSeparator != "." || options.dateFormat != "" || options.na != "" || len(config.ColumnNames) > 0
+}
+
+// newFormatWriter returns a format writer for the output formatting options, writing to next,
+// renaming the column_names of the config when rename is set
+func newFormatWriter(next reportWriter, rename bool) *formatWriter {
+	return &formatWriter{next: next, decimal: options.decimalSeparator, dateLayout: options.dateFormat, rename: rename}
+}
+
+// Write finds the numeric and date columns in the header, and formats their values in other records
+func (w *formatWriter) Write(record []string) error {
+	if w.numeric == nil {
+		w.numeric, w.dates = map[int]bool{}, map[int]bool{}
+		derived := map[string]bool{}
+		for _, col := range config.DerivedColumns {
+			derived[col.Name] = true
+		}
+		for i, col := range record {
+			w.numeric[i] = stringList(numericColumns).contains(col) || derived[col]
+			w.dates[i] = stringList(dateColumns).contains(col)
+		}
+		if w.rename {
+			record = renameColumns(record)
+		}
+		return w.next.Write(record)
+	}
+
+	record = append([]string{}, record...)
+	for i, value := range record {
+		switch {
+		case w.numeric[i] && value == "":
+			record[i] = options.na
+		case w.numeric[i] && w.decimal != ".":
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				record[i] = strings.Replace(value, ".", w.decimal, 1)
+			}
+		case w.dates[i] && w.dateLayout != "":
+			if t, err := time.Parse(timeStringLayout, value); err == nil {
+				record[i] = t.UTC().Format(w.dateLayout)
+			}
+		}
+	}
+	return w.next.Write(record)
+}
+
+// isLocalizedNumber returns true when value is a number formatted with the decimal separator
+func (w *formatWriter) isLocalizedNumber(value string) bool {
+	_, err := strconv.ParseFloat(strings.Replace(value, w.decimal, ".", 1), 64)
+	return err == nil
+}
+
+// Flush flushes the next writer
+func (w *formatWriter) Flush() {
+	w.next.Flush()
+}
+
+// renameColumns returns the header with the column_names of the config applied
+func renameColumns(header []string) []string {
+	renamed := append([]string{}, header...)
+	for name, newName := range config.ColumnNames {
+		for i, col := range header {
+			if col == name || strings.HasPrefix(col, name+" (") {
+				renamed[i] = newName
+			}
+		}
+	}
+	return renamed
+}
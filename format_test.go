@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSanitizeFormula(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"", ""},
+		{"checkout latency", "checkout latency"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+1+1", "'+1+1"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"-1.5", "-1.5"},
+		{"+2", "+2"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeFormula(tt.value); got != tt.want {
+			t.Errorf("sanitizeFormula(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCSVOutputLocalizedNumbers(t *testing.T) {
+	saved := options
+	defer func() { options = saved }()
+	options.sanitizeFormulas, options.decimalSeparator, options.na = true, ",", ""
+
+	tests := []struct {
+		name   string
+		record []string
+		want   string
+	}{
+		{"negative localized number", []string{"=cmd", "-1.5"}, "'=cmd,\"-1,5\"\n"},
+		{"positive localized number", []string{"latency", "99.5"}, "latency,\"99,5\"\n"},
+		{"formula in numeric column", []string{"latency", "=cmd"}, "latency,'=cmd\n"},
+		{"formula in derived column", []string{"latency", "-1+1"}, "latency,'-1+1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := newCSVWriter(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writer.format = newFormatWriter(writer, false)
+			if err := writer.format.Write([]string{"name", "target"}); err != nil {
+				t.Fatal(err)
+			}
+			writer.Flush()
+			buf.Reset()
+			if err := writer.format.Write(tt.record); err != nil {
+				t.Fatal(err)
+			}
+			writer.Flush()
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+
+	"slos/schema"
+)
+
+// gate results of a check
+const (
+	gatePass  = "PASS"
+	gateBlock = "BLOCK"
+)
+
+// gateCheck is a check of an slo gating a deploy
+type gateCheck struct {
+	slo    datadog.ServiceLevelObjective
+	check  string
+	result string
+	detail string
+}
+
+// runGate evaluates the slos of a service before a deploy: recent burn rates against the fast burn thresholds, and
+// each timeframe against the config budget_policy (or an exhausted error budget without one), writing the checks to
+// stdout as csv and exiting with status 1 when any blocks the deploy
+func runGate(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("gate", flag.ContinueOnError)
+	service := fs.String("service", "", "service: tag value of the SLOs gating the deploy e.g checkout (required)")
+	var freezeActions stringList
+	fs.Var(&freezeActions, "freeze-actions", "comma separated budget_policy actions blocking deploys e.g 'feature freeze' (default any matching rule)")
+	failOpen := fs.Bool("fail-open", false, "allow the deploy when SLO history can't be read, instead of blocking it")
+	parseFlags(fs, args)
+	if *service == "" {
+		fs.Usage()
+		exit(2)
+	}
+
+	tagQuery := "service:" + *service
+	slos, err := listOrgSLOs(ctx, options.limit, tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	if len(slos) == 0 {
+		fatalf("No SLOs tagged %s, the deploy can't be gated", tagQuery)
+	}
+
+	apiClient := newAPIClient()
+	now := runClock.Now().UTC()
+	var checks []gateCheck
+	for counter, slo := range slos {
+		if ctx.Err() != nil {
+			fatalf("Gate stopped, err: %s", ctx.Err())
+		}
+		log.Printf("(%d of %d) Gating s: %s", counter+1, len(slos), slo.GetId())
+		slo = withTargetOverride(slo)
+		if len(slo.Thresholds) == 0 {
+			continue
+		}
+		slis, errs := loadShortWindowSLIs(ctx, apiClient, slo, now)
+		checks = append(checks, burnRateChecks(slo, slis, errs, *failOpen)...)
+
+		buffer := &bufferWriter{}
+		var writer reportWriter = buffer
+		if len(config.BudgetPolicy) > 0 {
+			writer = newPolicyWriter(writer, config.BudgetPolicy)
+		}
+		if len(config.DerivedColumns) > 0 {
+			writer = &derivedWriter{next: writer, columns: config.DerivedColumns}
+		}
+		writer.Write(rowColumns())
+		for _, threshold := range slo.Thresholds {
+			if len(options.timeframes) > 0 && !options.timeframes.contains(string(threshold.Timeframe)) {
+				continue
+			}
+			if options.excludeTimeframes.contains(string(threshold.Timeframe)) {
+				continue
+			}
+			from, to, err := getSLOTimeSpanFromTimeframe(threshold.Timeframe, now)
+			if err != nil {
+				log.Printf("Unable to get time span from timeframe s: %s, tf: %s, err: %s", slo.GetId(), threshold.Timeframe, err)
+				continue
+			}
+			reportTimeSpan(ctx, apiClient, writer, reportRow{slo: slo, threshold: threshold, from: from, to: to})
+			if err := sleepContext(ctx, options.sleep); err != nil {
+				fatalf("Gate stopped, err: %s", err)
+			}
+		}
+		header := buffer.records[0]
+		for _, record := range buffer.records[1:] {
+			checks = append(checks, budgetCheck(slo, recordMap(header, record), freezeActions, *failOpen))
+		}
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	if err := writer.Write([]string{"name", "slo_id", "check", "result", "detail"}); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+	blocked := 0
+	for _, check := range checks {
+		if check.result == gateBlock {
+			blocked++
+			log.Printf("Deploy blocked by s: %s, %s: %s", check.slo.GetId(), check.check, check.detail)
+		}
+		if err := writer.Write([]string{check.slo.GetName(), check.slo.GetId(), check.check, check.result, check.detail}); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+	}
+	writer.Flush()
+	if blocked > 0 {
+		fatalf("Deploy of %s blocked by %d of %d checks of %d SLOs", *service, blocked, len(checks), len(slos))
+	}
+	log.Printf("Deploy of %s allowed, %d checks of %d SLOs passed", *service, len(checks), len(slos))
+}
+
+// burnRateChecks returns a check per burn window, blocking when the slo burns its first target's error budget faster
+// than the window's threshold, or unless failOpen when the window's history can't be read
+func burnRateChecks(slo datadog.ServiceLevelObjective, slis []*float64, errs []error, failOpen bool) []gateCheck {
+	rates, _ := burnRates(slis, slo.Thresholds[0].Target)
+	checks := make([]gateCheck, 0, len(rates))
+	for i, rate := range rates {
+		check := gateCheck{slo: slo, check: "burn_rate_" + burnWindows[i].name, result: gatePass}
+		switch {
+		case errs[i] != nil:
+			check.detail = "history unavailable: " + errs[i].Error()
+			if !failOpen {
+				check.result = gateBlock
+			}
+		case rate == nil:
+			check.detail = "no data"
+		case *rate > burnWindows[i].threshold:
+			check.result = gateBlock
+			check.detail = fmt.Sprintf("burn rate %.2f above %v", *rate, burnWindows[i].threshold)
+		default:
+			check.detail = fmt.Sprintf("burn rate %.2f within %v", *rate, burnWindows[i].threshold)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// budgetCheck returns the check of a timeframe row, blocking when a budget_policy rule (one of the freeze actions if
+// set) matches it, or without a budget_policy when its error budget is exhausted
+func budgetCheck(slo datadog.ServiceLevelObjective, row map[string]string, freezeActions stringList, failOpen bool) gateCheck {
+	check := gateCheck{slo: slo, check: "budget_" + row["timeframe"], result: gatePass}
+	if msg := row["error (only if applicable)"]; msg != "" && row["status"] != schema.StatusNoData {
+		check.detail = "history unavailable: " + msg
+		if !failOpen {
+			check.result = gateBlock
+		}
+		return check
+	}
+	consumed, err := strconv.ParseFloat(row["error_budget_consumed"], 64)
+	if len(config.BudgetPolicy) > 0 {
+		action := row[policyActionColumn]
+		if action != "" && (len(freezeActions) == 0 || freezeActions.contains(action)) {
+			check.result = gateBlock
+		}
+		check.detail = fmt.Sprintf("policy action: %q", action)
+		if err == nil {
+			check.detail += fmt.Sprintf(", %.2f%% of the error budget consumed", consumed)
+		}
+		return check
+	}
+	switch {
+	case err != nil:
+		check.detail = "no data"
+	case consumed >= 100:
+		check.result = gateBlock
+		check.detail = fmt.Sprintf("error budget exhausted, %.2f%% consumed", consumed)
+	default:
+		check.detail = fmt.Sprintf("%.2f%% of the error budget consumed", consumed)
+	}
+	return check
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestBurnRateChecks(t *testing.T) {
+	slo := datadog.ServiceLevelObjective{Thresholds: []datadog.SLOThreshold{{Target: 99}}}
+	sli := func(v float64) *float64 { return &v }
+	unavailable := errors.New("api error: 500 Internal Server Error")
+	tests := []struct {
+		name     string
+		slis     []*float64
+		errs     []error
+		failOpen bool
+		want     []string
+	}{
+		{"within thresholds", []*float64{sli(99.5), sli(99.5), sli(99.5)}, make([]error, 3), false, []string{gatePass, gatePass, gatePass}},
+		{"fast burn", []*float64{sli(80), sli(99.5), sli(99.5)}, make([]error, 3), false, []string{gateBlock, gatePass, gatePass}},
+		{"no data", make([]*float64, 3), make([]error, 3), false, []string{gatePass, gatePass, gatePass}},
+		{"history unavailable", []*float64{nil, sli(99.5), sli(99.5)}, []error{unavailable, nil, nil}, false, []string{gateBlock, gatePass, gatePass}},
+		{"history unavailable fail open", []*float64{nil, sli(99.5), sli(99.5)}, []error{unavailable, nil, nil}, true, []string{gatePass, gatePass, gatePass}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := burnRateChecks(slo, tt.slis, tt.errs, tt.failOpen)
+			if len(checks) != len(tt.want) {
+				t.Fatalf("got %d checks, want %d", len(checks), len(tt.want))
+			}
+			for i, check := range checks {
+				if check.result != tt.want[i] {
+					t.Errorf("%s: got %s (%s), want %s", check.check, check.result, check.detail, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBudgetCheck(t *testing.T) {
+	saved := config.BudgetPolicy
+	defer func() { config.BudgetPolicy = saved }()
+	slo := datadog.ServiceLevelObjective{}
+	row := func(consumed, errMsg, status, action string) map[string]string {
+		return map[string]string{
+			"timeframe":                  "30d",
+			"error_budget_consumed":      consumed,
+			"error (only if applicable)": errMsg,
+			"status":                     status,
+			policyActionColumn:           action,
+		}
+	}
+	policy := []*budgetPolicyRule{{When: "error_budget_consumed >= 100", Action: "feature freeze"}}
+	tests := []struct {
+		name          string
+		policy        []*budgetPolicyRule
+		row           map[string]string
+		freezeActions stringList
+		failOpen      bool
+		want          string
+	}{
+		{"budget left", nil, row("40.000000", "", StatusOK, ""), nil, false, gatePass},
+		{"budget exhausted", nil, row("120.000000", "", StatusBreached, ""), nil, false, gateBlock},
+		{"no data", nil, row("", "", StatusNoData, ""), nil, false, gatePass},
+		{"history unavailable", nil, row("", "api error: timeout", "", ""), nil, false, gateBlock},
+		{"history unavailable fail open", nil, row("", "api error: timeout", "", ""), nil, true, gatePass},
+		{"policy action", policy, row("120.000000", "", StatusBreached, "feature freeze"), nil, false, gateBlock},
+		{"policy without action", policy, row("120.000000", "", StatusBreached, ""), nil, false, gatePass},
+		{"policy action not freezing", policy, row("120.000000", "", StatusBreached, "feature freeze"), stringList{"rollback"}, false, gatePass},
+		{"policy freeze action", policy, row("120.000000", "", StatusBreached, "feature freeze"), stringList{"feature freeze"}, false, gateBlock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.BudgetPolicy = tt.policy
+			if got := budgetCheck(slo, tt.row, tt.freezeActions, tt.failOpen); got.result != tt.want {
+				t.Errorf("budgetCheck() = %s (%s), want %s", got.result, got.detail, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+)
+
+// runGrafanaDashboard writes a grafana dashboard json visualizing the slo metrics exported with -otlp-endpoint,
+// as stored by a prometheus compatible backend
+func runGrafanaDashboard(_ context.Context, args []string) {
+	fs := flag.NewFlagSet("grafana-dashboard", flag.ContinueOnError)
+	title := fs.String("title", "SLO Report", "dashboard title")
+	sliMetric := fs.String("sli-metric", "slo_sli_percent", "prometheus name of the slo.sli metric")
+	budgetMetric := fs.String("budget-metric", "slo_error_budget_consumed_percent", "prometheus name of the slo.error_budget_consumed metric")
+	output := fs.String("o", "", "path the dashboard json is written to (default stdout)")
+	parseFlags(fs, args)
+
+	content, err := json.MarshalIndent(grafanaDashboard(*title, *sliMetric, *budgetMetric), "", "  ")
+	if err != nil {
+		fatalf("Unable to generate dashboard, err: %s", err)
+	}
+	if *output == "" {
+		fmt.Println(string(content))
+		return
+	}
+	if err := ioutil.WriteFile(*output, content, 0644); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", *output, err)
+	}
+	log.Printf("Grafana dashboard written to: %s", *output)
+}
+
+// grafanaDashboard returns the dashboard model, panels are filtered by the timeframe variable, the exported metrics
+// have no team label
+func grafanaDashboard(title, sliMetric, budgetMetric string) map[string]interface{} {
+	datasource := map[string]string{"type": "prometheus", "uid": "${datasource}"}
+	selector := `{timeframe=~"$timeframe"}`
+	target := func(expr, legend string, instant bool) map[string]interface{} {
+		t := map[string]interface{}{"datasource": datasource, "expr": expr, "legendFormat": legend, "refId": "A"}
+		if instant {
+			t["instant"] = true
+			t["format"] = "table"
+		}
+		return t
+	}
+	panel := func(id int, panelType, title string, x, y, w, h int, targets ...map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"id":         id,
+			"type":       panelType,
+			"title":      title,
+			"datasource": datasource,
+			"gridPos":    map[string]int{"x": x, "y": y, "w": w, "h": h},
+			"targets":    targets,
+			"fieldConfig": map[string]interface{}{
+				"defaults":  map[string]interface{}{"unit": "percent"},
+				"overrides": []interface{}{},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"title":         title,
+		"uid":           "slo-report",
+		"schemaVersion": 36,
+		"editable":      true,
+		"time":          map[string]string{"from": "now-30d", "to": "now"},
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"name": "datasource", "type": "datasource", "query": "prometheus"},
+				map[string]interface{}{
+					"name":       "timeframe",
+					"type":       "query",
+					"datasource": datasource,
+					"query":      fmt.Sprintf("label_values(%s, timeframe)", sliMetric),
+					"includeAll": true,
+					"multi":      true,
+					"current":    map[string]interface{}{"text": "All", "value": "$__all"},
+				},
+			},
+		},
+		"panels": []interface{}{
+			panel(1, "timeseries", "SLI by SLO", 0, 0, 12, 9,
+				target(sliMetric+selector, "{{slo_name}} ({{timeframe}})", false)),
+			panel(2, "timeseries", "Error budget consumed by SLO", 12, 0, 12, 9,
+				target(budgetMetric+selector, "{{slo_name}} ({{timeframe}})", false)),
+			panel(3, "bargauge", "Top 10 error budget consumers", 0, 9, 12, 10,
+				target(fmt.Sprintf("topk(10, %s%s)", budgetMetric, selector), "{{slo_name}} ({{timeframe}})", true)),
+			panel(4, "table", "Latest SLI", 12, 9, 12, 10,
+				target(sliMetric+selector, "", true)),
+		},
+	}
+}
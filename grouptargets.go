@@ -0,0 +1,28 @@
+package main
+
+import "github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+
+// groupTargetColumn holds the group's own target of group rows, when group targets are configured
+const groupTargetColumn = "group_target"
+
+// groupTarget returns the configured target of the slo group e.g env:prod, the datadog api has no per group
+// targets so they are defined in the config group_targets
+func groupTarget(slo datadog.ServiceLevelObjective, group string) (float64, bool) {
+	target, found := config.GroupTargets[slo.GetId()][group]
+	return target, found
+}
+
+// withGroupTarget evaluates the group row against the group's own target, the error budget consumed is
+// recomputed from the sli since the history api computes it against the slo target
+func withGroupTarget(row reportRow) reportRow {
+	target, found := groupTarget(row.slo, row.group)
+	if !found {
+		return row
+	}
+	row.groupTarget = &target
+	if row.sliValue != nil {
+		consumed := 100 - errorBudgetRemaining(*row.sliValue, target)
+		row.errorBudgetConsumed = &consumed
+	}
+	return row
+}
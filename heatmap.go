@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// heatmap day statuses, by the day's burn rate of the slo's first target error budget
+const (
+	heatmapUp       = "up"
+	heatmapDegraded = "degraded"
+	heatmapDown     = "down"
+	heatmapNoData   = "no_data"
+	heatmapError    = "error"
+)
+
+// heatmapDay is an slo's status on a utc calendar day
+type heatmapDay struct {
+	Status string
+	SLI    *float64
+}
+
+// heatmapSLO is the daily status grid of an slo, oldest day first
+type heatmapSLO struct {
+	Name   string
+	ID     string
+	Target float64
+	Days   []heatmapDay
+}
+
+// heatmapTemplate renders the heatmap as a standalone html page, a row of colored day cells per slo
+var heatmapTemplate = template.Must(template.New("heatmap").Funcs(template.FuncMap{
+	"title": func(day heatmapDay) string {
+		if day.SLI == nil {
+			return day.Status
+		}
+		return fmt.Sprintf("%s, SLI %.3f%%", day.Status, *day.SLI)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>SLO daily status {{.From}} to {{.To}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: separate; border-spacing: 2px; }
+th { font-weight: normal; font-size: 11px; }
+th.slo { text-align: left; padding-right: 8px; font-size: 13px; }
+td { width: 14px; height: 14px; border-radius: 2px; }
+.up { background: #2da44e; }
+.degraded { background: #e3b341; }
+.down { background: #cf222e; }
+.no_data, .error { background: #d0d7de; }
+</style>
+</head>
+<body>
+<h1>SLO daily status</h1>
+<p>{{.From}} to {{.To}} (utc days), up: target met, degraded: error budget burned up to {{.DownBurnRate}}x the daily budget, down: burned faster</p>
+<table>
+<tr><th></th>{{range .Dates}}<th title="{{.}}">{{slice . 8}}</th>{{end}}</tr>
+{{range .SLOs}}<tr><th class="slo" title="{{.ID}}, target {{.Target}}%">{{.Name}}</th>{{range .Days}}<td class="{{.Status}}" title="{{title .}}"></td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// runHeatmap writes each slo's status on each of the last N utc days, up, degraded or down by how fast the day burned
+// its first target's error budget, as a csv grid of a row per slo and a column per day to -path, and optionally as
+// an html heatmap like the uptime widget
+func runHeatmap(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("heatmap", flag.ContinueOnError)
+	days := fs.Int("days", 30, "number of complete utc days before today in the heatmap")
+	downBurnRate := fs.Float64("down-burn-rate", 10, "burn rate of the daily error budget above which a day is down instead of degraded")
+	htmlPath := fs.String("html", "", "also render the heatmap as an html page to this path")
+	parseFlags(fs, args)
+	if *days <= 0 {
+		fatalf("Invalid days: %d, expected a positive number of days", *days)
+	}
+
+	today := startOfDay(runClock.Now().UTC())
+	from := today.AddDate(0, 0, -*days)
+	dates := make([]string, 0, *days)
+	for day := from; day.Before(today); day = day.Add(OneDay) {
+		dates = append(dates, day.Format("2006-01-02"))
+	}
+	log.Printf("SLO heatmap of %s to %s will be saved at: %s \n", dates[0], dates[len(dates)-1], options.filePath)
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	apiClient := newAPIClient()
+	var grid []heatmapSLO
+	for counter, slo := range slos {
+		if ctx.Err() != nil {
+			fatalf("Heatmap stopped after %d of %d SLOs, err: %s", counter, len(slos), ctx.Err())
+		}
+		slo = withTargetOverride(slo)
+		if len(slo.Thresholds) == 0 {
+			log.Printf("Skipping s: %s, err: slo has no thresholds", slo.GetId())
+			continue
+		}
+		// days are evaluated against the first configured target
+		threshold := slo.Thresholds[0]
+		log.Printf("(%d of %d) Getting daily SLO history s: %s", counter+1, len(slos), slo.GetId())
+		row := heatmapSLO{Name: slo.GetName(), ID: slo.GetId(), Target: threshold.GetTarget()}
+		for _, day := range splitDaily(reportRow{slo: slo, threshold: threshold, from: from, to: today}) {
+			history, err := getSLOHistory(ctx, apiClient, slo, threshold, day.from, day.to)
+			summary.recordHistoryCall(err)
+			if err != nil {
+				log.Printf("Unable to get slo history s: %s, d: %s, err: %s", slo.GetId(), day.period, err)
+				row.Days = append(row.Days, heatmapDay{Status: heatmapError})
+			} else {
+				row.Days = append(row.Days, heatmapStatus(history, threshold.GetTarget(), *downBurnRate))
+			}
+			if err := sleepContext(ctx, options.sleep); err != nil {
+				fatalf("Heatmap stopped after %d of %d SLOs, err: %s", counter, len(slos), err)
+			}
+		}
+		grid = append(grid, row)
+	}
+
+	if err := writeHeatmapCSV(options.filePath, dates, grid); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+	}
+	if *htmlPath != "" {
+		if err := writeHeatmapHTML(*htmlPath, dates, grid, *downBurnRate); err != nil {
+			fatalf("Unable to write to file: %s, err: %s", *htmlPath, err)
+		}
+		log.Printf("SLO heatmap rendered at: %s", *htmlPath)
+	}
+	log.Printf("Done - Daily status of %d SLOs over %d days", len(grid), len(dates))
+}
+
+// heatmapStatus returns the day's status from its history: up when the sli meets the target, degraded when the day
+// burned its error budget up to downBurnRate times faster than sustainable, down when faster
+func heatmapStatus(history *datadog.SLOHistoryResponse, target, downBurnRate float64) heatmapDay {
+	sli, ok := history.Data.Overall.GetSliValueOk()
+	if !ok {
+		return heatmapDay{Status: heatmapNoData}
+	}
+	// metric slos without events in the day have no data rather than a 0 or 100% sli
+	if series, found := history.Data.GetSeriesOk(); found && series.Denominator.Sum == 0 {
+		return heatmapDay{Status: heatmapNoData}
+	}
+	day := heatmapDay{Status: heatmapUp, SLI: sli}
+	if *sli >= target {
+		return day
+	}
+	rates, _ := burnRates([]*float64{sli}, target)
+	day.Status = heatmapDegraded
+	if rates[0] != nil && *rates[0] > downBurnRate {
+		day.Status = heatmapDown
+	}
+	return day
+}
+
+// writeHeatmapCSV writes the grid as csv, a row per slo and a column per day
+func writeHeatmapCSV(path string, dates []string, grid []heatmapSLO) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer, err := newCSVWriter(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Write(append([]string{"name", "slo_id", "target"}, dates...)); err != nil {
+		file.Close()
+		return err
+	}
+	for _, slo := range grid {
+		data := []string{slo.Name, slo.ID, fmt.Sprintf("%f", slo.Target)}
+		for _, day := range slo.Days {
+			data = append(data, day.Status)
+		}
+		if err := writer.Write(data); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeHeatmapHTML renders the grid as a standalone html page
+func writeHeatmapHTML(path string, dates []string, grid []heatmapSLO, downBurnRate float64) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = heatmapTemplate.Execute(file, map[string]interface{}{
+		"From":         dates[0],
+		"To":           dates[len(dates)-1],
+		"Dates":        dates,
+		"SLOs":         grid,
+		"DownBurnRate": downBurnRate,
+	})
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
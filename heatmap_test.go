@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestHeatmapStatus(t *testing.T) {
+	sli := func(v float64) *float64 { return &v }
+	history := func(sli *float64, series *datadog.SLOHistoryMetrics) *datadog.SLOHistoryResponse {
+		return &datadog.SLOHistoryResponse{Data: &datadog.SLOHistoryResponseData{
+			Overall: &datadog.SLOHistorySLIData{SliValue: sli},
+			Series:  series,
+		}}
+	}
+	events := func(total float64) *datadog.SLOHistoryMetrics {
+		return &datadog.SLOHistoryMetrics{Denominator: datadog.SLOHistoryMetricsSeries{Sum: total}}
+	}
+	tests := []struct {
+		name    string
+		history *datadog.SLOHistoryResponse
+		want    string
+	}{
+		{"target met", history(sli(99.95), nil), heatmapUp},
+		{"degraded", history(sli(99.5), nil), heatmapDegraded},
+		{"down", history(sli(98), nil), heatmapDown},
+		{"burn rate below the threshold", history(sli(99.05), nil), heatmapDegraded},
+		{"no sli", history(nil, nil), heatmapNoData},
+		{"metric slo without events", history(sli(100), events(0)), heatmapNoData},
+		{"metric slo with events", history(sli(99.95), events(1000)), heatmapUp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a 99.9% target, the budget burns 10x faster than sustainable at 99%
+			if got := heatmapStatus(tt.history, 99.9, 10); got.Status != tt.want {
+				t.Errorf("heatmapStatus() = %s, want %s", got.Status, tt.want)
+			}
+		})
+	}
+}
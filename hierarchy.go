@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// hierarchyLevels are the org hierarchy columns added to the report, from lowest to highest level
+var hierarchyLevels = []string{"service", "team", "department"}
+
+// hierarchyConfig maps slos into the org hierarchy, the service comes from the slo's service: tag,
+// the team from its team: tag or the service mapping, and the department from the team mapping
+type hierarchyConfig struct {
+	ServiceTeams    map[string]string `json:"service_teams"`
+	TeamDepartments map[string]string `json:"team_departments"`
+}
+
+// sloHierarchy returns the slo's service, team and department, empty when unknown
+func sloHierarchy(slo datadog.ServiceLevelObjective) []string {
+	service := sloTagValue(slo, "service")
+	team := sloTagValue(slo, "team")
+	if team == "" {
+		team = config.Hierarchy.ServiceTeams[service]
+	}
+	return []string{service, team, config.Hierarchy.TeamDepartments[team]}
+}
+
+// rollupKey identifies a value at a hierarchy level e.g team payments
+type rollupKey struct {
+	level, value string
+}
+
+// rollupStats summarizes the rows of a hierarchy level value
+type rollupStats struct {
+	rows, ok, warning, breached, errors int
+	sliSum                              float64
+	sliCount                            int
+	maxBudgetConsumed                   *float64
+}
+
+// rollupWriter summarizes records at each hierarchy level before writing them to the next writer
+type rollupWriter struct {
+	next   reportWriter
+	header []string
+	stats  map[rollupKey]*rollupStats
+}
+
+// newRollupWriter returns a rollup writer writing records to next
+func newRollupWriter(next reportWriter) *rollupWriter {
+	return &rollupWriter{next: next, stats: map[rollupKey]*rollupStats{}}
+}
+
+// Write adds the record to the summary of each of its hierarchy level values
+func (w *rollupWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		return w.next.Write(record)
+	}
+	lookup := recordLookup(w.header, record)
+	for _, level := range hierarchyLevels {
+		value, _ := lookup(level)
+		if value == "" {
+			value = "(none)"
+		}
+		key := rollupKey{level, value}
+		stats, found := w.stats[key]
+		if !found {
+			stats = &rollupStats{}
+			w.stats[key] = stats
+		}
+		stats.add(lookup)
+	}
+	return w.next.Write(record)
+}
+
+// Flush flushes the next writer
+func (w *rollupWriter) Flush() {
+	w.next.Flush()
+}
+
+// add adds a row to the stats
+func (s *rollupStats) add(lookup fieldLookup) {
+	s.rows++
+	status, _ := lookup("status")
+	switch status {
+	case StatusOK:
+		s.ok++
+	case StatusWarning:
+		s.warning++
+	case StatusBreached:
+		s.breached++
+	}
+	if errStr, _ := lookup("error"); errStr != "" {
+		s.errors++
+	}
+	if sli, err := lookupNumber(lookup, "overall_status"); err == nil {
+		s.sliSum += sli
+		s.sliCount++
+	}
+	if budget, err := lookupNumber(lookup, "error_budget_consumed"); err == nil && (s.maxBudgetConsumed == nil || budget > *s.maxBudgetConsumed) {
+		s.maxBudgetConsumed = &budget
+	}
+}
+
+// rollupPath returns the rollup summary path for a report e.g /tmp/slo_report.rollup.csv for /tmp/slo_report.csv,
+// encrypted reports get an encrypted rollup e.g /tmp/slo_report.csv.rollup.csv.age
+func rollupPath(reportPath string) string {
+	if options.encryptWith != "" {
+		plain := strings.TrimSuffix(reportPath, filepath.Ext(reportPath))
+		return encryptedPath(plain+".rollup.csv", options.encryptWith)
+	}
+	return strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ".rollup.csv"
+}
+
+// writeRollup writes (encrypted if enabled) the summary of each hierarchy level value, ordered by level and value
+func (w *rollupWriter) writeRollup(path string) error {
+	keys := make([]rollupKey, 0, len(w.stats))
+	for key := range w.stats {
+		keys = append(keys, key)
+	}
+	levelIndex := map[string]int{}
+	for i, level := range hierarchyLevels {
+		levelIndex[level] = i
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].level != keys[j].level {
+			return levelIndex[keys[i].level] < levelIndex[keys[j].level]
+		}
+		return keys[i].value < keys[j].value
+	})
+
+	file, err := createReportFile(path)
+	if err != nil {
+		return err
+	}
+	writer, err := newCSVWriter(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	cols := []string{"level", "value", "rows", "ok", "warning", "breached", "errors", "avg_sli", "max_error_budget_consumed"}
+	if err := writer.Write(cols); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		stats := w.stats[key]
+		avgSLI, maxBudgetConsumed := options.na, options.na
+		if stats.sliCount > 0 {
+			avgSLI = fmt.Sprintf("%f", stats.sliSum/float64(stats.sliCount))
+		}
+		if stats.maxBudgetConsumed != nil {
+			maxBudgetConsumed = fmt.Sprintf("%f", *stats.maxBudgetConsumed)
+		}
+		data := []string{
+			key.level,
+			key.value,
+			strconv.Itoa(stats.rows),
+			strconv.Itoa(stats.ok),
+			strconv.Itoa(stats.warning),
+			strconv.Itoa(stats.breached),
+			strconv.Itoa(stats.errors),
+			avgSLI,
+			maxBudgetConsumed,
+		}
+		if err := writer.Write(data); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// ignoreRule excludes the slos whose id is the pattern, or whose name matches it as a glob e.g test-*
+type ignoreRule struct {
+	pattern, reason string
+}
+
+// ignoredSLO is an slo left out of the report by the -ignore-file, listed in the run summary
+type ignoredSLO struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ignoreRules are loaded from -ignore-file
+var ignoreRules []ignoreRule
+
+// loadIgnoreFile reads the rules of the ignore file, a PATTERN,REASON line per rule, the reason is optional
+// and lines starting with # are comments
+func loadIgnoreFile(filePath string) ([]ignoreRule, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var rules []ignoreRule
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rules, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rule := ignoreRule{pattern: strings.TrimSpace(record[0])}
+		if rule.pattern == "" {
+			continue
+		}
+		if _, err := path.Match(rule.pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %s", rule.pattern)
+		}
+		if len(record) > 1 {
+			rule.reason = strings.TrimSpace(strings.Join(record[1:], ", "))
+		}
+		rules = append(rules, rule)
+	}
+}
+
+// ignoreReason returns the reason of the first rule the slo matches, and false when it isn't ignored
+func ignoreReason(slo datadog.ServiceLevelObjective) (string, bool) {
+	for _, rule := range ignoreRules {
+		if globMatch(rule.pattern, slo.GetName()) || rule.pattern == slo.GetId() {
+			return rule.reason, true
+		}
+	}
+	return "", false
+}
+
+// globMatch returns true when the name matches the glob pattern, like path.Match but * and ? also match / since slo
+// names often have one e.g checkout / latency
+func globMatch(pattern, name string) bool {
+	matched, _ := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), strings.ReplaceAll(name, "/", "\x00"))
+	return matched
+}
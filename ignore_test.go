@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestLoadIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []ignoreRule
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"comments and blank lines", "# test SLOs\n\n  \ntest-*,test SLOs\n", []ignoreRule{{"test-*", "test SLOs"}}, false},
+		{"no reason", "abc123\n", []ignoreRule{{"abc123", ""}}, false},
+		{"quoted reason with commas", "abc123,\"broken query, see INC-42\"\n", []ignoreRule{{"abc123", "broken query, see INC-42"}}, false},
+		{"unquoted reason with commas", "abc123, broken query, see INC-42\n", []ignoreRule{{"abc123", "broken query, see INC-42"}}, false},
+		{"spaces trimmed", "  test-* ,  test SLOs  \n", []ignoreRule{{"test-*", "test SLOs"}}, false},
+		{"invalid pattern", "test-[,broken\n", nil, true},
+		{"unterminated quote", "abc123,\"broken\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ignore.txt")
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			rules, err := loadIgnoreFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadIgnoreFile() = %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(rules, tt.want) {
+				t.Errorf("loadIgnoreFile() = %+v, want %+v", rules, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnoreReason(t *testing.T) {
+	saved := ignoreRules
+	defer func() { ignoreRules = saved }()
+	ignoreRules = []ignoreRule{
+		{"abc123", "broken query"},
+		{"test-*", "test SLOs"},
+		{"checkout / *", "checkout is migrating"},
+		{"*latency?", "flaky"},
+	}
+	tests := []struct {
+		name    string
+		id      string
+		sloName string
+		reason  string
+		ignored bool
+	}{
+		{"id", "abc123", "checkout availability", "broken query", true},
+		{"name glob", "xyz", "test-payments", "test SLOs", true},
+		{"glob star matches slash", "xyz", "test-a/b", "test SLOs", true},
+		{"slash in pattern", "xyz", "checkout / availability", "checkout is migrating", true},
+		{"question mark matches slash", "xyz", "api latency/", "flaky", true},
+		{"first matching rule", "abc123", "test-payments", "broken query", true},
+		{"glob is not a prefix match", "xyz", "my test-payments", "", false},
+		{"id is not a glob", "abc1234", "payments", "", false},
+		{"not ignored", "xyz", "payments", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slo := datadog.ServiceLevelObjective{}
+			slo.SetId(tt.id)
+			slo.SetName(tt.sloName)
+			reason, ignored := ignoreReason(slo)
+			if reason != tt.reason || ignored != tt.ignored {
+				t.Errorf("ignoreReason() = %q, %t, want %q, %t", reason, ignored, tt.reason, tt.ignored)
+			}
+		})
+	}
+}
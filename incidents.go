@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// incidentColumn holds the public ids of the incidents of the slo's services or teams overlapping the window, with
+// -incidents
+const incidentColumn = "incident_ids"
+
+// ddIncident is an incident from the datadog incidents api
+type ddIncident struct {
+	Attributes struct {
+		PublicID            int64      `json:"public_id"`
+		Created             time.Time  `json:"created"`
+		Resolved            *time.Time `json:"resolved"`
+		CustomerImpactStart *time.Time `json:"customer_impact_start"`
+		CustomerImpactEnd   *time.Time `json:"customer_impact_end"`
+		Fields              map[string]struct {
+			Value interface{} `json:"value"`
+		} `json:"fields"`
+	} `json:"attributes"`
+}
+
+// incident is an incident's public id, when it impacted customers, and the lower case services and teams it's for
+type incident struct {
+	publicID int64
+	interval timeInterval
+	services []string
+	teams    []string
+}
+
+// orgIncidents are the incidents of the org, loaded once per run with -incidents
+var orgIncidents []incident
+
+// loadIncidents returns all incidents, active from their customer impact start (or creation) until its end (or their
+// resolution), open incidents until now
+func loadIncidents(ctx context.Context) ([]incident, error) {
+	var all []incident
+	pageSize := 100
+	for offset := 0; ; offset += pageSize {
+		var resp struct {
+			Data []ddIncident `json:"data"`
+		}
+		query := url.Values{
+			"page[size]":   {fmt.Sprintf("%d", pageSize)},
+			"page[offset]": {fmt.Sprintf("%d", offset)},
+		}
+		if err := datadogSend(ctx, http.MethodGet, "/api/v2/incidents", query, nil, &resp); err != nil {
+			return nil, err
+		}
+		for _, data := range resp.Data {
+			attributes := data.Attributes
+			found := incident{
+				publicID: attributes.PublicID,
+				interval: timeInterval{from: attributes.Created, to: runClock.Now()},
+				services: incidentFieldValues(attributes.Fields["services"].Value),
+				teams:    incidentFieldValues(attributes.Fields["teams"].Value),
+			}
+			if attributes.CustomerImpactStart != nil {
+				found.interval.from = *attributes.CustomerImpactStart
+			}
+			if attributes.CustomerImpactEnd != nil {
+				found.interval.to = *attributes.CustomerImpactEnd
+			} else if attributes.Resolved != nil {
+				found.interval.to = *attributes.Resolved
+			}
+			all = append(all, found)
+		}
+		if len(resp.Data) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// incidentFieldValues returns the lower case values of an incident field, a single value or a list of them
+func incidentFieldValues(value interface{}) []string {
+	var values []string
+	switch v := value.(type) {
+	case string:
+		values = append(values, strings.ToLower(v))
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, strings.ToLower(s))
+			}
+		}
+	}
+	return values
+}
+
+// sloTagValues returns the lower case values of each of the slo's key: tags
+func sloTagValues(slo datadog.ServiceLevelObjective, key string) []string {
+	var values []string
+	for _, tag := range slo.GetTags() {
+		if strings.HasPrefix(tag, key+":") {
+			values = append(values, strings.ToLower(strings.TrimPrefix(tag, key+":")))
+		}
+	}
+	return values
+}
+
+// sloIncidents returns the public ids of the incidents overlapping the window for one of the slo's service: or team:
+// tags, space separated in public id order
+func sloIncidents(slo datadog.ServiceLevelObjective, from, to time.Time) string {
+	services, sloTeams := sloTagValues(slo, "service"), sloTagValues(slo, "team")
+	var ids []int64
+	for _, candidate := range orgIncidents {
+		if !candidate.interval.from.Before(to) || !candidate.interval.to.After(from) {
+			continue
+		}
+		if sharesValue(services, candidate.services) || sharesValue(sloTeams, candidate.teams) {
+			ids = append(ids, candidate.publicID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, fmt.Sprintf("%d", id))
+	}
+	return strings.Join(values, " ")
+}
+
+// sharesValue returns true when a value is in both lists
+func sharesValue(a, b []string) bool {
+	for _, value := range a {
+		if stringList(b).contains(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// checksumPath returns the checksums path for a report e.g /tmp/slo_report.sha256 for /tmp/slo_report.csv
+func checksumPath(reportPath string) string {
+	return strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ".sha256"
+}
+
+// writeChecksums writes the sha256 checksums of the files in sha256sum format (verify with sha256sum -c
+// from the files' directory) and returns the checksums path
+func writeChecksums(reportPath string, paths []string) (string, error) {
+	var lines strings.Builder
+	for _, path := range paths {
+		sum, err := sha256File(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&lines, "%s  %s\n", sum, filepath.Base(path))
+	}
+	path := checksumPath(reportPath)
+	return path, ioutil.WriteFile(path, []byte(lines.String()), 0644)
+}
+
+// sha256File returns the hex encoded sha256 of the file content
+func sha256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// signFile writes a detached signature of the file using gpg or cosign (which must be installed),
+// returning the signature path
+func signFile(path, signer, key string) (string, error) {
+	var cmd *exec.Cmd
+	var sigPath string
+	switch signer {
+	case "gpg":
+		sigPath = path + ".asc"
+		args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--output", sigPath}
+		if key != "" {
+			args = append(args, "--local-user", key)
+		}
+		cmd = exec.Command("gpg", append(args, path)...)
+	case "cosign":
+		if key == "" {
+			return "", fmt.Errorf("cosign signing requires -sign-key")
+		}
+		sigPath = path + ".sig"
+		cmd = exec.Command("cosign", "sign-blob", "--yes", "--key", key, "--output-signature", sigPath, path)
+	default:
+		return "", fmt.Errorf("unsupported signer: %s, expected gpg or cosign", signer)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%s failed: %s, output: %s", signer, err, strings.TrimSpace(string(output)))
+	}
+	return sigPath, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"slos/schema"
+)
+
+// junitTestSuites is the root of a junit xml report, the format CI systems display test results from
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is the test suite of an slo, with a test case per report row
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	ID       string          `xml:"id,attr,omitempty"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a report row, failed when the window is breached, in error when its history could not be read
+// and skipped when it has no data
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+// junitOutput is the output of a test case, the row's values
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+// junitMessage is the message and type of a failure, error or skipped test case
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// openJUnitOutput creates a junit xml report file, encrypted if enabled, written once all rows are received
+func openJUnitOutput(path string) (*output, error) {
+	file, err := createReportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := &junitWriter{}
+	return &output{format: "junit", path: path, writer: writer, close: func() error {
+		if err := writer.render(file); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}}, nil
+}
+
+// junitWriter collects report rows as junit test cases, grouped in a test suite per slo in the order they come
+type junitWriter struct {
+	header []string
+	suites []junitTestSuite
+	// index of each slo's suite by slo id
+	index map[string]int
+}
+
+// Write keeps the header and adds other records as test cases
+func (w *junitWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		w.index = map[string]int{}
+		return nil
+	}
+	row := recordMap(w.header, record)
+	i, found := w.index[row["slo_id"]]
+	if !found {
+		i = len(w.suites)
+		w.index[row["slo_id"]] = i
+		w.suites = append(w.suites, junitTestSuite{Name: row["name"], ID: row["slo_id"]})
+	}
+	suite := &w.suites[i]
+	testCase := newJUnitTestCase(row)
+	suite.Tests++
+	switch {
+	case testCase.Error != nil:
+		suite.Errors++
+	case testCase.Failure != nil:
+		suite.Failures++
+	case testCase.Skipped != nil:
+		suite.Skipped++
+	}
+	suite.Cases = append(suite.Cases, testCase)
+	return nil
+}
+
+// Flush is a no-op, the report is written when the output is closed
+func (w *junitWriter) Flush() {}
+
+// render writes the test suites as junit xml
+func (w *junitWriter) render(out io.Writer) error {
+	report := junitTestSuites{Name: "slo-report", Suites: w.suites}
+	for _, suite := range w.suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+	}
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// newJUnitTestCase returns the test case of a report row, named after its timeframe, group and period
+func newJUnitTestCase(row map[string]string) junitTestCase {
+	name := []string{row["timeframe"]}
+	for _, col := range []string{"group", "period"} {
+		if row[col] != "" {
+			name = append(name, row[col])
+		}
+	}
+	testCase := junitTestCase{
+		Name:      strings.Join(name, " "),
+		ClassName: row["name"],
+		SystemOut: &junitOutput{Text: fmt.Sprintf("slo_id: %s\ntarget: %s\nsli: %s\nerror_budget_consumed: %s\nstatus: %s",
+			row["slo_id"], row["target"], row["overall_status"], row["error_budget_consumed"], row["status"])},
+	}
+	switch {
+	case row["error (only if applicable)"] != "" && row["status"] != schema.StatusNoData:
+		testCase.Error = &junitMessage{Message: row["error (only if applicable)"], Type: row["error_type"]}
+	case row["status"] == schema.StatusBreached:
+		testCase.Failure = &junitMessage{Type: schema.StatusBreached, Message: fmt.Sprintf("SLI %s below target %s, %s%% of the error budget consumed",
+			row["overall_status"], row["target"], row["error_budget_consumed"])}
+	case row["status"] == schema.StatusNoData || row["status"] == schema.StatusDeleted:
+		testCase.Skipped = &junitMessage{Message: "status " + row["status"]}
+	}
+	return testCase
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// kafkaWriter publishes each record as a json message keyed by slo_id to a kafka topic through a
+// kafka rest proxy (e.g confluent rest proxy v2), records are batched until Flush
+type kafkaWriter struct {
+	next    reportWriter
+	url     string
+	topic   string
+	client  *http.Client
+	header  []string
+	pending []kafkaRecord
+}
+
+// kafkaRecord is a rest proxy json record
+type kafkaRecord struct {
+	Key   string            `json:"key"`
+	Value map[string]string `json:"value"`
+}
+
+// newKafkaWriter returns a writer publishing to topic through the rest proxy at url before writing to next
+func newKafkaWriter(next reportWriter, url, topic string) *kafkaWriter {
+	return &kafkaWriter{
+		next:   next,
+		url:    strings.TrimRight(url, "/"),
+		topic:  topic,
+		client: &http.Client{Timeout: 30 * time.Second, Transport: userAgentTransport{}},
+	}
+}
+
+// Write queues the record for publishing and writes it to the next writer
+func (k *kafkaWriter) Write(record []string) error {
+	if k.header == nil {
+		k.header = record
+		return k.next.Write(record)
+	}
+	value := recordMap(k.header, record)
+	k.pending = append(k.pending, kafkaRecord{Key: value["slo_id"], Value: value})
+	return k.next.Write(record)
+}
+
+// Flush publishes the queued records, publishing errors are logged rather than failing the report
+func (k *kafkaWriter) Flush() {
+	if len(k.pending) > 0 {
+		if err := k.publish(k.pending); err != nil {
+			log.Printf("Unable to publish %d rows to kafka topic: %s, err: %s", len(k.pending), k.topic, err)
+		}
+		k.pending = nil
+	}
+	k.next.Flush()
+}
+
+// publish posts the records to the rest proxy topic endpoint
+func (k *kafkaWriter) publish(records []kafkaRecord) error {
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/topics/%s", k.url, k.topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// recordMap returns the record values keyed by header column name
+func recordMap(header, record []string) map[string]string {
+	m := make(map[string]string, len(header))
+	for i, col := range header {
+		if i < len(record) {
+			m[col] = record[i]
+		}
+	}
+	return m
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// keychainService is the service name the datadog keys are stored under in the os keychain
+const keychainService = "slo-report"
+
+// runLogin prompts for the datadog keys and stores them in the os keychain
+// (macOS Keychain, Windows Credential Manager or libsecret), they are then read when not set in the environment
+func runLogin(_ context.Context, args []string) {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	site := fs.String("site", "", "datadog site stored with the keys e.g datadoghq.eu (default datadoghq.com)")
+	parseFlags(fs, args)
+
+	stdin := bufio.NewReader(os.Stdin)
+	values := map[string]string{"DD_SITE": *site}
+	for _, name := range []string{"DD_API_KEY", "DD_APP_KEY"} {
+		fmt.Fprintf(os.Stderr, "%s: ", name)
+		value, _ := stdin.ReadString('\n')
+		if values[name] = strings.TrimSpace(value); values[name] == "" {
+			fatalf("No %s entered", name)
+		}
+	}
+	for _, name := range credentialVariables {
+		if values[name] == "" {
+			continue
+		}
+		if err := keychainSet(name, values[name]); err != nil {
+			fatalf("Unable to store %s in the keychain, err: %s", name, err)
+		}
+	}
+	log.Printf("Datadog keys stored in the keychain")
+}
+
+// runLogout removes the datadog keys from the os keychain
+func runLogout(_ context.Context, args []string) {
+	fs := flag.NewFlagSet("logout", flag.ContinueOnError)
+	parseFlags(fs, args)
+
+	for _, name := range credentialVariables {
+		if err := keychainDelete(name); err != nil {
+			log.Printf("Unable to remove %s from the keychain, err: %s", name, err)
+		}
+	}
+	log.Printf("Datadog keys removed from the keychain")
+}
+
+// loadKeychainCredentials sets the datadog environment variables that are not set from the os keychain, if stored
+func loadKeychainCredentials() {
+	for _, name := range credentialVariables {
+		if os.Getenv(name) != "" {
+			continue
+		}
+		if value, err := keychainGet(name); err == nil && value != "" {
+			os.Setenv(name, value)
+		}
+	}
+}
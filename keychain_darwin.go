@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainSet stores the secret in the macOS Keychain, passing it to security on stdin so it is not in the process list
+func keychainSet(account, secret string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %q\n", keychainService, account, secret))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// keychainGet returns the secret from the macOS Keychain
+func keychainGet(account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", account, "-w").Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// keychainDelete removes the secret from the macOS Keychain
+func keychainDelete(account string) error {
+	return exec.Command("security", "delete-generic-password", "-s", keychainService, "-a", account).Run()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// keychainSet stores the secret with libsecret (secret-tool must be installed), passing it on stdin
+func keychainSet(account, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", keychainService+" "+account, "service", keychainService, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	return cmd.Run()
+}
+
+// keychainGet returns the secret from libsecret
+func keychainGet(account string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keychainService, "account", account).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// keychainDelete removes the secret from libsecret
+func keychainDelete(account string) error {
+	return exec.Command("secret-tool", "clear", "service", keychainService, "account", account).Run()
+}
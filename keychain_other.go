@@ -0,0 +1,24 @@
+//go:build !darwin && !linux && !windows
+// +build !darwin,!linux,!windows
+
+package main
+
+import "fmt"
+
+// errNoKeychain is returned on platforms without a supported keychain
+var errNoKeychain = fmt.Errorf("no supported keychain on this platform")
+
+// keychainSet is not supported on this platform
+func keychainSet(account, secret string) error {
+	return errNoKeychain
+}
+
+// keychainGet is not supported on this platform
+func keychainGet(account string) (string, error) {
+	return "", errNoKeychain
+}
+
+// keychainDelete is not supported on this platform
+func keychainDelete(account string) error {
+	return errNoKeychain
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// runList writes SLOs matching the tag query to stdout, optionally with ownership and lifecycle details
+func runList(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	details := fs.Bool("details", false, "include creator, created_at, modified_at and last history data point")
+	sortBy := fs.String("sort", "", "sort SLOs by age (oldest created first) or modified (least recently modified first)")
+	parseFlags(fs, args)
+
+	if *sortBy != "" && *sortBy != "age" && *sortBy != "modified" {
+		fatalf("Unsupported sort: %s, expected age or modified", *sortBy)
+	}
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	sortSLOs(slos, *sortBy)
+
+	cols := []string{"name", "slo_id", "type"}
+	if *details {
+		cols = append(cols,
+			"creator",
+			"created_at (utc)",
+			"modified_at (utc)",
+			"age_days",
+			"last_data_point (utc)",
+			"error (only if applicable)",
+		)
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	if err := writer.Write(cols); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+
+	apiClient := newAPIClient()
+	now := runClock.Now().UTC()
+	for counter, slo := range slos {
+		data := []string{slo.GetName(), slo.GetId(), string(slo.GetType())}
+		var stopped error
+		if *details {
+			log.Printf("(%d of %d) Getting last data point s: %s", counter+1, len(slos), slo.GetId())
+			lastPoint, err := getLastHistoryDataPoint(ctx, apiClient, slo, now)
+			errStr := ""
+			if err != nil {
+				log.Printf("Unable to get last data point s: %s, err: %s", slo.GetId(), err)
+				errStr = err.Error()
+			}
+			created := time.Unix(slo.GetCreatedAt(), 0).UTC()
+			data = append(data,
+				slo.Creator.GetEmail(),
+				fmt.Sprintf("%s", created),
+				fmt.Sprintf("%s", time.Unix(slo.GetModifiedAt(), 0).UTC()),
+				fmt.Sprintf("%d", int64(now.Sub(created)/OneDay)),
+				formatOptionalTime(lastPoint),
+				errStr,
+			)
+			stopped = sleepContext(ctx, options.sleep)
+		}
+		if err := writer.Write(data); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+		if stopped != nil {
+			writer.Flush()
+			fatalf("List stopped after %d of %d SLOs, err: %s", counter+1, len(slos), stopped)
+		}
+	}
+}
+
+// sortSLOs sorts slos in place, oldest first, by creation (age) or modification (modified) time
+func sortSLOs(slos []datadog.ServiceLevelObjective, sortBy string) {
+	switch sortBy {
+	case "age":
+		sort.SliceStable(slos, func(i, j int) bool {
+			return slos[i].GetCreatedAt() < slos[j].GetCreatedAt()
+		})
+	case "modified":
+		sort.SliceStable(slos, func(i, j int) bool {
+			return slos[i].GetModifiedAt() < slos[j].GetModifiedAt()
+		})
+	}
+}
+
+// getLastHistoryDataPoint returns the time of the most recent history data point in the last 90 days,
+// a zero time is returned when the slo has no data in that span
+func getLastHistoryDataPoint(
+	ctx context.Context,
+	apiClient *datadog.APIClient,
+	slo datadog.ServiceLevelObjective,
+	now time.Time,
+) (time.Time, error) {
+	if len(slo.Thresholds) == 0 {
+		return time.Time{}, fmt.Errorf("slo has no thresholds")
+	}
+	history, err := getSLOHistory(ctx, apiClient, slo, slo.Thresholds[0], now.Add(-NinetyDays), now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// monitor slos report state transitions as [timestamp, state] pairs in seconds
+	points := history.Data.Overall.GetHistory()
+	if len(points) > 0 && len(points[len(points)-1]) > 0 {
+		return time.Unix(int64(points[len(points)-1][0]), 0).UTC(), nil
+	}
+
+	// metric slos report series timestamps in milliseconds
+	if series, ok := history.Data.GetSeriesOk(); ok && len(series.Times) > 0 {
+		return time.Unix(0, int64(series.Times[len(series.Times)-1])*int64(time.Millisecond)).UTC(), nil
+	}
+
+	return time.Time{}, nil
+}
+
+// formatOptionalTime formats t, returning an empty string for the zero time
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s", t.UTC())
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockPollInterval is how often a held lock is retried while waiting
+const lockPollInterval = 30 * time.Second
+
+// runLock prevents overlapping runs, locks older than the ttl are considered stale (e.g a killed run) and taken over
+type runLock interface {
+	// tryAcquire takes the lock, returning false when another run holds it
+	tryAcquire(ttl time.Duration) (bool, error)
+	// renew extends the held lock by ttl, so long runs are not taken over
+	renew(ttl time.Duration) error
+	release() error
+}
+
+// newRunLock returns the lock for the -lock value, a dynamodb://table/key item or a local lockfile path
+func newRunLock(value string) (runLock, error) {
+	if !strings.HasPrefix(value, "dynamodb://") {
+		return &fileLock{path: value}, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, "dynamodb://"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid lock: %s, expected dynamodb://table/key", value)
+	}
+	region, err := awsRegionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &dynamoDBLock{table: parts[0], key: parts[1], region: region, owner: lockOwner()}, nil
+}
+
+// heldLock is the lock held for the run, if any
+var heldLock runLock
+
+// stopLockRenewal stops renewing the held lock, if any
+var stopLockRenewal func()
+
+// holdRunLock holds the acquired lock for the run, renewing it every third of the ttl until it is released
+func holdRunLock(lock runLock, ttl time.Duration) {
+	heldLock = lock
+	done := make(chan struct{})
+	stopLockRenewal = func() { close(done) }
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-runClock.After(ttl / 3):
+				if err := lock.renew(ttl); err != nil {
+					log.Printf("Unable to renew lock: %s, err: %s", options.lock, err)
+				}
+			}
+		}
+	}()
+}
+
+// releaseRunLock releases the lock held for the run, if any, so runs exiting with an error can release it too
+func releaseRunLock() {
+	if heldLock == nil {
+		return
+	}
+	stopLockRenewal()
+	if err := heldLock.release(); err != nil {
+		log.Printf("Unable to release lock: %s, err: %s", options.lock, err)
+	}
+	heldLock = nil
+}
+
+// exit releases the lock held for the run before exiting with code, os.Exit skips deferred calls
+func exit(code int) {
+	releaseRunLock()
+	os.Exit(code)
+}
+
+// fatalf is log.Fatalf releasing the lock held for the run first, for fatal errors once the lock may be held
+func fatalf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+	exit(1)
+}
+
+// acquireRunLock takes the lock, waiting up to wait for another run to release it
+func acquireRunLock(lock runLock, ttl, wait time.Duration) error {
+	deadline := runClock.Now().Add(wait)
+	for {
+		acquired, err := lock.tryAcquire(ttl)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+		if !runClock.Now().Before(deadline) {
+			return fmt.Errorf("lock is held by another run")
+		}
+		log.Printf("Lock is held by another run, waiting ...")
+		runClock.Sleep(lockPollInterval)
+	}
+}
+
+// lockOwner identifies this run e.g host:pid
+func lockOwner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// fileLock is a local lockfile holding the owner, created exclusively
+type fileLock struct {
+	path string
+}
+
+// tryAcquire creates the lockfile, replacing it when older than the ttl
+func (l *fileLock) tryAcquire(ttl time.Duration) (bool, error) {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		info, statErr := os.Stat(l.path)
+		if statErr != nil || since(info.ModTime()) < ttl {
+			return false, nil
+		}
+		log.Printf("Removing stale lock: %s", l.path)
+		if err := os.Remove(l.path); err != nil {
+			return false, err
+		}
+		file, err = os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) {
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "%s %s\n", lockOwner(), runClock.Now().UTC().Format(time.RFC3339))
+	return err == nil, err
+}
+
+// renew touches the lockfile, its age is checked against the ttl
+func (l *fileLock) renew(time.Duration) error {
+	now := runClock.Now()
+	return os.Chtimes(l.path, now, now)
+}
+
+// release removes the lockfile
+func (l *fileLock) release() error {
+	return os.Remove(l.path)
+}
+
+// dynamoDBLock is an item in a dynamodb table (with a lock_id string partition key) written with a conditional put,
+// so runs on different hosts are serialized
+type dynamoDBLock struct {
+	table, key, region, owner string
+}
+
+// tryAcquire puts the lock item unless an unexpired one exists
+func (l *dynamoDBLock) tryAcquire(ttl time.Duration) (bool, error) {
+	now := runClock.Now().Unix()
+	_, err := l.call("PutItem", map[string]interface{}{
+		"TableName": l.table,
+		"Item": map[string]interface{}{
+			"lock_id":    map[string]string{"S": l.key},
+			"owner":      map[string]string{"S": l.owner},
+			"expires_at": map[string]string{"N": strconv.FormatInt(now+int64(ttl.Seconds()), 10)},
+		},
+		"ConditionExpression":       "attribute_not_exists(lock_id) OR expires_at < :now",
+		"ExpressionAttributeValues": map[string]interface{}{":now": map[string]string{"N": strconv.FormatInt(now, 10)}},
+	})
+	if err != nil && strings.Contains(err.Error(), "ConditionalCheckFailedException") {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// renew extends the lock item's expiry if this run still owns it
+func (l *dynamoDBLock) renew(ttl time.Duration) error {
+	_, err := l.call("UpdateItem", map[string]interface{}{
+		"TableName":                l.table,
+		"Key":                      map[string]interface{}{"lock_id": map[string]string{"S": l.key}},
+		"UpdateExpression":         "SET expires_at = :expires_at",
+		"ConditionExpression":      "#owner = :owner",
+		"ExpressionAttributeNames": map[string]string{"#owner": "owner"},
+		"ExpressionAttributeValues": map[string]interface{}{
+			":owner":      map[string]string{"S": l.owner},
+			":expires_at": map[string]string{"N": strconv.FormatInt(runClock.Now().Add(ttl).Unix(), 10)},
+		},
+	})
+	return err
+}
+
+// release deletes the lock item if this run still owns it
+func (l *dynamoDBLock) release() error {
+	_, err := l.call("DeleteItem", map[string]interface{}{
+		"TableName":                 l.table,
+		"Key":                       map[string]interface{}{"lock_id": map[string]string{"S": l.key}},
+		"ConditionExpression":       "#owner = :owner",
+		"ExpressionAttributeNames":  map[string]string{"#owner": "owner"},
+		"ExpressionAttributeValues": map[string]interface{}{":owner": map[string]string{"S": l.owner}},
+	})
+	return err
+}
+
+// call calls a dynamodb json protocol action
+func (l *dynamoDBLock) call(action string, input interface{}) ([]byte, error) {
+	return awsJSON("dynamodb", l.region, "1.0", "DynamoDB_20120810."+action, input)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		age      time.Duration
+		renew    bool
+		acquired bool
+	}{
+		{"held", time.Minute, false, false},
+		{"stale", 2 * time.Hour, false, true},
+		{"stale renewed", 2 * time.Hour, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := useFakeClock(t, time.Date(2024, time.March, 31, 2, 30, 0, 0, time.UTC))
+			path := filepath.Join(t.TempDir(), "slo_report.lock")
+			holder := &fileLock{path: path}
+			if acquired, err := holder.tryAcquire(time.Hour); err != nil || !acquired {
+				t.Fatalf("tryAcquire() = %t, %v, want the lock", acquired, err)
+			}
+			modified := clock.Now().Add(-tt.age)
+			if err := os.Chtimes(path, modified, modified); err != nil {
+				t.Fatal(err)
+			}
+			if tt.renew {
+				if err := holder.renew(time.Hour); err != nil {
+					t.Fatal(err)
+				}
+			}
+			acquired, err := (&fileLock{path: path}).tryAcquire(time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if acquired != tt.acquired {
+				t.Errorf("tryAcquire() = %t, want %t", acquired, tt.acquired)
+			}
+		})
+	}
+}
+
+// heldRunLock is a lock held by another run for a number of attempts
+type heldRunLock struct {
+	heldFor, attempts int
+}
+
+func (l *heldRunLock) tryAcquire(time.Duration) (bool, error) {
+	l.attempts++
+	return l.attempts > l.heldFor, nil
+}
+
+func (l *heldRunLock) renew(time.Duration) error { return nil }
+
+func (l *heldRunLock) release() error { return nil }
+
+func TestAcquireRunLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		heldFor  int
+		wait     time.Duration
+		wantErr  bool
+		attempts int
+	}{
+		{"free", 0, 0, false, 1},
+		{"held without waiting", 1, 0, true, 1},
+		{"released while waiting", 2, 5 * time.Minute, false, 3},
+		{"held past the wait", 100, 5 * time.Minute, true, 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, time.March, 31, 2, 30, 0, 0, time.UTC)
+			clock := useFakeClock(t, start)
+			lock := &heldRunLock{heldFor: tt.heldFor}
+			if err := acquireRunLock(lock, time.Hour, tt.wait); (err != nil) != tt.wantErr {
+				t.Errorf("acquireRunLock() = %v, want error %t", err, tt.wantErr)
+			}
+			if lock.attempts != tt.attempts {
+				t.Errorf("acquireRunLock() attempts = %d, want %d", lock.attempts, tt.attempts)
+			}
+			if waited := clock.Now().Sub(start); waited != time.Duration(tt.attempts-1)*lockPollInterval {
+				t.Errorf("acquireRunLock() waited %s, want %d polls", waited, tt.attempts-1)
+			}
+		})
+	}
+}
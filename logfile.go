@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// rotatingLog is an append only log file rotated to path.1, path.2 ... once it reaches a size or an age
+type rotatingLog struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+	keep    int
+	file    *os.File
+	size    int64
+	opened  time.Time
+}
+
+// openRotatingLog opens the log at path for appending, a log last written longer than maxAge ago is rotated first,
+// a maxSize or maxAge of 0 disables that rotation
+func openRotatingLog(path string, maxSize int64, maxAge time.Duration, keep int) (*rotatingLog, error) {
+	l := &rotatingLog{path: path, maxSize: maxSize, maxAge: maxAge, keep: keep}
+	if info, err := os.Stat(path); err == nil && maxAge > 0 && since(info.ModTime()) >= maxAge {
+		if err := l.shift(); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open opens the log file for appending
+func (l *rotatingLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file, l.size, l.opened = file, info.Size(), runClock.Now()
+	return nil
+}
+
+// shift renames the log to path.1, path.1 to path.2 and so on, dropping the logs beyond keep
+func (l *rotatingLog) shift() error {
+	if l.keep <= 0 {
+		return os.Remove(l.path)
+	}
+	os.Remove(fmt.Sprintf("%s.%d", l.path, l.keep))
+	for i := l.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	return os.Rename(l.path, l.path+".1")
+}
+
+// Write appends p to the log, rotating it first when p would take it over the max size or it is over the max age
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if (l.maxSize > 0 && l.size > 0 && l.size+int64(len(p)) > l.maxSize) || (l.maxAge > 0 && since(l.opened) >= l.maxAge) {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate closes the log, shifts it and opens a new one
+func (l *rotatingLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	if err := l.shift(); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// Close closes the log file
+func (l *rotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
@@ -2,15 +2,22 @@ package main
 
 import (
 	"context"
-	"encoding/csv"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math/rand"
+	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+
+	"slos/schema"
 )
 
 const (
@@ -26,139 +33,1135 @@ const (
 
 // options struct to define options
 var options struct {
-	filePath string
-	tagQuery string
-	limit    int64
-	sleep    time.Duration
+	filePath    string
+	outputs     stringList
+	configPath  string
+	tagQuery    string
+	query       string
+	sloSource   string
+	limit       int64
+	shard       shard
+	maxSLOs     int
+	sample      float64
+	sleep       time.Duration
+	listRetries int
+	runTimeout  time.Duration
+	// stallTimeout stops runs without progress for this long
+	stallTimeout time.Duration
+	// timeframeConcurrency is the number of history calls of an slo in flight
+	timeframeConcurrency int
+	userAgent            string
+	runLabel             string
+	noPreflight          bool
+	readOnly             bool
+	summaryPath          string
+	statusFile           string
+	errorPolicy          errorPolicy
+
+	// where logs are written, in addition to stderr
+	logFile    string
+	logMaxSize int64
+	logMaxAge  time.Duration
+	logKeep    int
+
+	// where the run is profiled
+	pprofAddr  string
+	cpuProfile string
+	memProfile string
+
+	// where datadog keys are read from, in addition to the environment
+	credentialHelper string
+	apiKeySSM        string
+	appKeySSM        string
+
+	// what is evaluated
+	groupBy             string
+	daily               bool
+	weeks               int
+	windows             windowList
+	historyChunk        window
+	eventCounts         bool
+	noData              string
+	downtimes           bool
+	muteStatus          bool
+	incidents           bool
+	fastBurn            bool
+	forecast            bool
+	baselineReports     string
+	anomalyStddev       float64
+	previousPeriod      bool
+	regressionThreshold float64
+	timeframes          stringList
+	excludeTimeframes   stringList
+	targetOverride      float64
+	targetOverrideFile  string
+	overridesFile       string
+	ignoreFile          string
+	slaTargetsFile      string
+	resolveTeams        bool
+	requireTeam         bool
+
+	// how the report is written
+	format           string
+	filter           string
+	tagColumns       stringList
+	decimalSeparator string
+	dateFormat       string
+	bom              bool
+	crlf             bool
+	excel            bool
+	sanitizeFormulas bool
+	na               string
+	rawJSON          bool
+	rawDir           string
+	exportDir        string
+	checksum         bool
+	signWith         string
+	signKey          string
+	encryptWith      string
+	recipients       stringList
+
+	// where rows are published
+	kafkaURL   string
+	kafkaTopic string
+
+	// where run completion is notified
+	notebook        string
+	teamReports     bool
+	smtpAddr        string
+	smtpFrom        string
+	snsTopicARN     string
+	sqsQueueURL     string
+	notifyOn        string
+	notifyMaxErrors int
+
+	// where telemetry is exported
+	otlpEndpoint string
+
+	// user commands run with the rows and when the report is complete
+	execRow   string
+	execAfter string
+
+	// how overlapping runs are prevented
+	lock     string
+	lockTTL  time.Duration
+	lockWait time.Duration
+}
+
+// subcommands maps subcommand names to their handlers, running without a subcommand generates the report
+var subcommands = map[string]func(ctx context.Context, args []string){
+	"achievability":     runAchievability,
+	"audit-alerts":      runAuditAlerts,
+	"backup":            runBackup,
+	"bench":             runBench,
+	"check-permissions": runCheckPermissions,
+	"clone":             runClone,
+	"convert":           runConvert,
+	"dashboard":         runDashboard,
+	"delete":            runDelete,
+	"drift":             runDrift,
+	"gate":              runGate,
+	"heatmap":           runHeatmap,
+	"grafana-dashboard": runGrafanaDashboard,
+	"list":              runList,
+	"login":             runLogin,
+	"logout":            runLogout,
+	"merge":             runMerge,
+	"monthly":           runMonthly,
+	"provision-alerts":  runProvisionAlerts,
+	"restore":           runRestore,
+	"scorecard":         runScorecard,
+	"snapshot":          runSnapshot,
+	"tag":               runTag,
 }
 
 func scriptUsage() {
-	fmt.Printf("Usage: %s [OPTIONS] argument ...\n", os.Args[0])
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("Usage: %s [OPTIONS] [SUBCOMMAND] argument ...\n", os.Args[0])
 	fmt.Println("\n Please make sure following environment variables are set DD_API_KEY and DD_APP_KEY")
+	fmt.Printf("\n Subcommands: %s (run `%s SUBCOMMAND -help` for options)\n", strings.Join(names, ", "), os.Args[0])
 	flag.PrintDefaults()
 }
 
 func init() {
-	flag.StringVar(&options.filePath, "path", "/tmp/slo_report.csv", "path for csv file")
+	flag.StringVar(&options.filePath, "path", defaultReportPath(), "path for csv file")
+	flag.StringVar(&options.configPath, "config", "", "path of a json config file e.g for derived_columns")
+	flag.StringVar(&options.format, "format", "csv", "report format, csv, json (json lines, written to path), github (GitHub Actions annotations of breached, warning and failed rows printed to stdout), gitlab (GitLab code quality json of those rows, written to path), junit (junit xml test report of a test case per row for CI systems, written to path), xlsx (excel workbook, written to path), parquet (typed columns for data tools, written to path) or table (printed to the terminal)")
+	flag.Var(&options.outputs, "output", "comma separated report outputs FORMAT:PATH written in the same run e.g csv:/tmp/slo_report.csv,json:/tmp/slo_report.jsonl,table (default -format written to -path)")
+	flag.StringVar(&options.filter, "filter", "", "only write rows matching the expression e.g 'error_budget_consumed > 80 && timeframe == \"30d\"'")
+	flag.Var(&options.tagColumns, "tag-columns", "comma separated SLO tag keys written to their own tag_<key> columns e.g team,env,tier")
+	flag.StringVar(&options.decimalSeparator, "decimal-separator", ".", "decimal separator of numbers in the output e.g , for spreadsheets in locales using decimal commas")
+	flag.StringVar(&options.dateFormat, "date-format", "", "go time layout of the from/to columns e.g '02.01.2006 15:04' (default 2006-01-02 15:04:05 +0000 UTC)")
+	flag.BoolVar(&options.bom, "bom", false, "start csv files with a utf-8 byte order mark")
+	flag.BoolVar(&options.crlf, "crlf", false, "end csv lines with crlf")
+	flag.BoolVar(&options.excel, "excel", false, "write csv files excel opens as is, implies -bom, -crlf and -date-format '"+excelDateFormat+"' unless set")
+	flag.BoolVar(&options.sanitizeFormulas, "sanitize-formulas", true, "prefix csv values starting with = + - @ with a quote, so spreadsheets don't evaluate them as formulas")
+	flag.StringVar(&options.na, "na", "", "placeholder written for missing numbers (no sli, no error budget data) e.g NA or null (default empty)")
+	flag.BoolVar(&options.rawJSON, "raw-json", false, "include the raw slo history response json in a raw_response column, for debugging")
+	flag.StringVar(&options.rawDir, "raw-dir", "", "write raw slo history responses to json files in this directory, their paths are included in a raw_response column")
+	flag.StringVar(&options.exportDir, "export-definitions", "", "write each SLO's full definition json to this directory, a point in time backup paired with the report")
+	flag.BoolVar(&options.checksum, "checksum", false, "write sha256 checksums of the report and manifest next to the report")
+	flag.StringVar(&options.signWith, "sign-with", "", "sign the report checksums with gpg or cosign (must be installed), implies -checksum")
+	flag.StringVar(&options.signKey, "sign-key", "", "key used to sign, gpg key id (default key if empty) or cosign key path")
+	flag.StringVar(&options.encryptWith, "encrypt-with", "", "encrypt the report with age or gpg (must be installed), .age or .gpg is appended to the path")
+	flag.Var(&options.recipients, "recipients", "comma separated age recipients or gpg key ids the report is encrypted for")
+	flag.StringVar(&options.kafkaURL, "kafka-rest-url", "", "kafka rest proxy url e.g http://localhost:8082, each row is published as json keyed by slo_id")
+	flag.StringVar(&options.kafkaTopic, "kafka-topic", "slo-report", "kafka topic rows are published to")
+	flag.StringVar(&options.snsTopicARN, "notify-sns-topic", "", "sns topic arn a run summary json is published to when the report is complete")
+	flag.StringVar(&options.sqsQueueURL, "notify-sqs-queue", "", "sqs queue url a run summary json is sent to when the report is complete")
+	flag.StringVar(&options.notebook, "notebook", "", "also create a datadog notebook with this name (the run date is appended) with the run summary, rows by status and the SLOs with the most error budget consumed, for reliability reviews")
+	flag.BoolVar(&options.teamReports, "team-reports", false, "also split the report by team (team: tag, or the hierarchy team) into a csv per team next to the report, and send each team its own rows to the slack webhook / emails of the config team_notifications")
+	flag.StringVar(&options.smtpAddr, "smtp-addr", "", "smtp server host:port team reports are emailed through, authenticated with SMTP_USERNAME and SMTP_PASSWORD when set")
+	flag.StringVar(&options.smtpFrom, "smtp-from", "slo-report@localhost", "sender address of team report emails")
+	flag.StringVar(&options.notifyOn, "notify-on", "always", "when runs are notified to sns / sqs: always or failure (the run was stopped by -error-policy or has more than -notify-max-errors error rows)")
+	flag.IntVar(&options.notifyMaxErrors, "notify-max-errors", 0, "error rows a run may have before -notify-on failure notifies it")
+	flag.StringVar(&options.otlpEndpoint, "otlp-endpoint", "", "opentelemetry collector otlp/http endpoint e.g http://localhost:4318, sli/error budget metrics and a run trace are exported")
+	flag.StringVar(&options.lock, "lock", "", "lockfile path or dynamodb://table/key item held for the run, a run finding it held exits (or waits, see -lock-wait)")
+	flag.DurationVar(&options.lockTTL, "lock-ttl", 6*time.Hour, "age after which a lock left by a killed run is taken over, the lock is renewed while the run holds it")
+	flag.DurationVar(&options.lockWait, "lock-wait", 0, "how long to wait for a held lock before exiting")
+	flag.StringVar(&options.execRow, "exec-row", "", "command started for the run, each row is written to its stdin as a json line")
+	flag.StringVar(&options.execAfter, "exec-after", "", "command run when the report is complete, a template of the run summary e.g 'upload.sh {{.Path}} {{.ManifestPath}}'")
+	flag.StringVar(&options.credentialHelper, "credential-helper", "", "command whose stdout sets DD_API_KEY, DD_APP_KEY and DD_SITE as KEY=VALUE lines")
+	flag.StringVar(&options.apiKeySSM, "api-key-ssm", "", "aws ssm parameter store parameter DD_API_KEY is read from (decrypted) e.g /datadog/api_key")
+	flag.StringVar(&options.appKeySSM, "app-key-ssm", "", "aws ssm parameter store parameter DD_APP_KEY is read from (decrypted) e.g /datadog/app_key")
+	flag.Var(&options.errorPolicy, "error-policy", "what api errors do: continue (write error rows), fail-fast (stop at the first) or max-errors=N (stop after N), a stopped run exits with status 1 after writing the rows so far")
+	flag.StringVar(&options.statusFile, "status-file", "", "also write the progress dumped on SIGUSR1 (kill -USR1 <pid>) as json to this path")
+	flag.StringVar(&options.summaryPath, "summary-json", "", "also write the end of run summary (calls, failures by error type, duration) as json to this path")
+	flag.StringVar(&options.logFile, "log-file", "", "also write the logs to this file, rotated to FILE.1, FILE.2 ... by -log-max-size and -log-max-age")
+	flag.Int64Var(&options.logMaxSize, "log-max-size", 100, "size in megabytes after which the log file is rotated, 0 never rotates on size")
+	flag.DurationVar(&options.logMaxAge, "log-max-age", 0, "age after which the log file is rotated e.g 24h, a log last written longer ago is rotated when the run starts (default never)")
+	flag.IntVar(&options.logKeep, "log-keep", 5, "number of rotated log files kept")
+	flag.StringVar(&options.pprofAddr, "pprof", "", "address the net/http/pprof endpoints are served on during the run e.g localhost:6060")
+	flag.StringVar(&options.cpuProfile, "cpuprofile", "", "write a cpu profile of the run to this file")
+	flag.StringVar(&options.memProfile, "memprofile", "", "write a heap profile at the end of the run to this file")
+	flag.BoolVar(&options.noPreflight, "no-preflight", false, "skip checking the datadog keys before the run")
+	flag.BoolVar(&options.readOnly, "read-only", true, "refuse to run subcommands and options modifying datadog (clone, dashboard, delete, provision-alerts, restore, tag, -notebook), -read-only=false allows them, dry runs are always allowed")
 	flag.StringVar(&options.tagQuery, "tagQuery", "", "tag query to filter results based on a single SLO tag e.g team:ninja")
+	flag.StringVar(&options.sloSource, "slo-source", "", "list the SLOs from saved definitions instead of the api: a json file (snapshot, list SLOs response or array) or a backup/-export-dir directory or .tar.gz archive, filtered by -tagQuery")
+	flag.StringVar(&options.query, "query", "", "full text SLO search query (name, description and facets) used instead of -tagQuery e.g 'checkout team:ninja'")
 	flag.Int64Var(&options.limit, "limit", 1000, "limit SLOs fetched in each get_all call")
+	flag.StringVar(&options.userAgent, "user-agent", "", "product of the User-Agent of api calls, default slo-report/VERSION, the platform and -run-label are appended")
+	flag.StringVar(&options.runLabel, "run-label", "", "label of the run added to the User-Agent of api calls e.g nightly-prod, to identify scheduled runs in usage attribution and egress logs")
+	flag.IntVar(&options.listRetries, "list-retries", 3, "retries of a failed SLO list page (rate limited, server or network errors), pages are paced by the rate limit headers")
+	flag.Var(&options.shard, "shard", "process only shard INDEX of COUNT e.g 2/5, slos are partitioned by a hash of their id so parallel runs cover each slo once")
+	flag.IntVar(&options.maxSLOs, "max-slos", 0, "process at most N of the matching SLOs, e.g to smoke test a configuration (default all)")
+	flag.Float64Var(&options.sample, "sample", 0, "process a random fraction of the matching SLOs e.g 0.1 (default all)")
+	flag.IntVar(&options.timeframeConcurrency, "timeframe-concurrency", 3, "history calls of an SLO's timeframes (and -window) in flight at once, their rows are written in order, 1 fetches them one at a time")
+	flag.DurationVar(&options.runTimeout, "run-timeout", 0, "stop the run after this long e.g 2h, rows written so far are kept and the exit status is 1 (default no timeout), SIGINT and SIGTERM also stop it")
+	flag.DurationVar(&options.stallTimeout, "stall-timeout", 0, "stop the run when no SLO is listed or processed and no history call completes for this long e.g 15m, logging the progress and goroutine stacks, it is killed if still running a minute later (default never)")
 	flag.DurationVar(&options.sleep, "sleep", 100*time.Millisecond, "sleep time between slo history calls for each slo")
+	flag.BoolVar(&options.daily, "daily", false, "split each timeframe into utc calendar days and write a row per day")
+	flag.IntVar(&options.weeks, "weeks", 0, "write a weekly rollup row per SLO for each of the last N complete iso weeks instead of the SLO timeframes")
+	flag.Var(&options.timeframes, "timeframes", "comma separated SLO threshold timeframes to report e.g 30d,90d (default all)")
+	flag.Var(&options.excludeTimeframes, "exclude-timeframes", "comma separated SLO threshold timeframes not reported e.g custom,90d")
+	flag.BoolVar(&options.eventCounts, "event-counts", false, "add good_events and total_events columns, the numerator and denominator sums of metric SLOs in the window")
+	flag.StringVar(&options.baselineReports, "baseline-reports", "", "glob of previous report csv files e.g 'reports/*.csv', rows whose error budget consumed is more than -anomaly-stddev above their historical mean are flagged in an anomaly column")
+	flag.BoolVar(&options.previousPeriod, "previous-period", false, "also get the history of the same length window before each window, adding previous_sli, sli_change, budget_consumed_change and regressed columns")
+	flag.Float64Var(&options.regressionThreshold, "regression-threshold", 10, "error budget consumed increase, in percentage points of the budget, since the previous period flagged as regressed")
+	flag.Float64Var(&options.anomalyStddev, "anomaly-stddev", 3, "standard deviations above the historical mean error budget consumed flagged as an anomaly")
+	flag.BoolVar(&options.forecast, "forecast", false, "add forecast columns projecting metric SLOs' SLI at the end of the calendar month from the trend of the month so far, with a 95% confidence band")
+	flag.BoolVar(&options.fastBurn, "fast-burn", false, "also get each SLO's 1h, 6h and 24h SLI, adding burn rate columns and an active incidents file of SLOs burning error budget faster than multi window alerting thresholds")
+	flag.BoolVar(&options.downtimes, "downtimes", false, "add a downtime_coverage column, the percentage of the window covered by scheduled downtimes of the SLO's monitors")
+	flag.BoolVar(&options.muteStatus, "mute-status", false, "add downtime_hours, muted_monitors_now and masked_by_mute_now columns for monitor SLOs, flagging OK or WARNING rows whose monitors were in downtime during the window or are muted now, implies -downtimes")
+	flag.BoolVar(&options.incidents, "incidents", false, "add an incident_ids column, the public ids of datadog incidents of the SLO's service: or team: tags overlapping the window")
+	flag.StringVar(&options.noData, "no-data", "no_data", "status of windows without data (e.g no events): no_data (NO_DATA), pass (OK) or fail (BREACHED)")
+	flag.Var(&options.historyChunk, "history-chunk", "fetch history of windows longer than this in chunks of this many days merged into one row e.g 15d, for slos whose 90d history calls time out")
+	flag.Var(&options.windows, "window", "comma separated rolling windows in days evaluated for every SLO in addition to its timeframes e.g 14d,45d")
+	flag.Float64Var(&options.targetOverride, "target-override", 0, "what-if target used instead of every SLO's configured targets e.g 99.95")
+	flag.StringVar(&options.slaTargetsFile, "sla-targets", "", "path of a json file of contractual SLA targets per SLO id or service compared in sla_target, sla_status and sla_risk columns e.g {\"slos\": {\"slo_id\": 99.5}, \"services\": {\"checkout\": 99.9}}")
+	flag.StringVar(&options.ignoreFile, "ignore-file", "", "path of a file of SLOs left out of the report, a line per SLO id or name glob pattern with an optional reason listed in the run summary e.g 'test-*,test SLOs'")
+	flag.StringVar(&options.overridesFile, "overrides", "", "path of a json file of options for special case SLOs by id or tag: skip, target, windows and labels written to label_<key> columns e.g {\"slos\": {\"slo_id\": {\"skip\": true}}, \"tags\": {\"team:legacy\": {\"target\": 99, \"windows\": [\"14d\"], \"labels\": {\"owner\": \"platform\"}}}}")
+	flag.StringVar(&options.targetOverrideFile, "target-override-file", "", "path of a json file of per SLO what-if targets e.g {\"slo_id\": 99.95}")
+	flag.BoolVar(&options.resolveTeams, "resolve-teams", false, "add team_name and team_handle columns for the SLO team: tag from the datadog teams api")
+	flag.BoolVar(&options.requireTeam, "require-team", false, "write an error row instead of history for SLOs whose team: tag matches no datadog team, implies -resolve-teams")
+	flag.StringVar(&options.groupBy, "group-by", "", "also write a row per SLO group with a value for this tag dimension e.g datacenter")
 }
 
 func main() {
+	rand.Seed(time.Now().UnixNano())
 	flag.Usage = scriptUsage
 	flag.Parse()
+	if options.logFile != "" {
+		logFile, err := openRotatingLog(options.logFile, options.logMaxSize*1024*1024, options.logMaxAge, options.logKeep)
+		if err != nil {
+			log.Fatalf("Unable to open log file: %s, err: %s", options.logFile, err)
+		}
+		defer logFile.Close()
+		log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+	}
+	if options.credentialHelper != "" {
+		if err := runCredentialHelper(options.credentialHelper); err != nil {
+			log.Fatalf("Credential helper failed: %s, err: %s", options.credentialHelper, err)
+		}
+	}
+	if err := loadSSMCredentials(); err != nil {
+		log.Fatalf("Unable to read datadog keys from ssm, err: %s", err)
+	}
+	loadKeychainCredentials()
 	log.Printf("Please make sure following environment variables are set DD_API_KEY and DD_APP_KEY \n")
-	log.Printf("SLO report file will be saved at: %s \n", options.filePath)
 
-	limit := options.limit
-	slos, err := getAllSLOs(limit, options.tagQuery)
+	if options.baselineReports != "" {
+		loaded, err := loadBaselines(options.baselineReports)
+		if err != nil {
+			log.Fatalf("Unable to load baseline reports: %s, err: %s", options.baselineReports, err)
+		}
+		baselines = loaded
+	}
+
+	if options.slaTargetsFile != "" {
+		targets, err := loadSLATargets(options.slaTargetsFile)
+		if err != nil {
+			log.Fatalf("Unable to load sla targets: %s, err: %s", options.slaTargetsFile, err)
+		}
+		contractTargets = targets
+	}
+
+	if options.ignoreFile != "" {
+		rules, err := loadIgnoreFile(options.ignoreFile)
+		if err != nil {
+			log.Fatalf("Unable to load ignore file: %s, err: %s", options.ignoreFile, err)
+		}
+		ignoreRules = rules
+	}
+
+	if options.overridesFile != "" {
+		overrides, err := loadOverrides(options.overridesFile)
+		if err != nil {
+			log.Fatalf("Unable to load overrides: %s, err: %s", options.overridesFile, err)
+		}
+		sloOverrides = overrides
+	}
+
+	// options implying others are resolved first, the config column_names are checked against the enabled columns
+	if options.requireTeam {
+		options.resolveTeams = true
+	}
+	if options.muteStatus {
+		options.downtimes = true
+	}
+
+	if options.configPath != "" {
+		if err := loadConfig(options.configPath); err != nil {
+			log.Fatalf("Unable to load config: %s, err: %s", options.configPath, err)
+		}
+	}
+
+	if options.targetOverrideFile != "" {
+		overrides, err := loadTargetOverrides(options.targetOverrideFile)
+		if err != nil {
+			log.Fatalf("Unable to load target overrides: %s, err: %s", options.targetOverrideFile, err)
+		}
+		targetOverrides = overrides
+	}
+
+	if options.sample < 0 || options.sample > 1 {
+		log.Fatalf("Invalid sample: %v, expected a fraction between 0 and 1", options.sample)
+	}
+
+	if options.excel {
+		options.bom, options.crlf = true, true
+		if options.dateFormat == "" {
+			options.dateFormat = excelDateFormat
+		}
+	}
+
+	if options.noData != "no_data" && options.noData != "pass" && options.noData != "fail" {
+		log.Fatalf("Invalid no-data: %s, expected no_data, pass or fail", options.noData)
+	}
+
+	if options.notebook != "" {
+		requireWritable("-notebook")
+	}
+	if options.notifyOn != "always" && options.notifyOn != "failure" {
+		log.Fatalf("Invalid notify-on: %s, expected always or failure", options.notifyOn)
+	}
+
+	if options.query != "" && options.tagQuery != "" {
+		log.Fatalf("Use either -query or -tagQuery")
+	}
+
+	ctx, cancel := newRunContext()
+	defer cancel()
+	if options.resolveTeams {
+		loaded, err := loadTeams(ctx)
+		if err != nil {
+			log.Fatalf("Unable to load datadog teams, err: %s", err)
+		}
+		teams = loaded
+	}
+
+	if options.otlpEndpoint != "" {
+		telemetry = newOtelExporter(options.otlpEndpoint)
+	}
+
+	if options.encryptWith != "" {
+		options.filePath = encryptedPath(options.filePath, options.encryptWith)
+	}
+
+	if options.filter != "" {
+		filter, err := parseRowFilter(options.filter)
+		if err != nil {
+			log.Fatalf("Invalid filter: %s, err: %s", options.filter, err)
+		}
+		rowFilter = filter
+	}
+
+	watchProgressSignal()
+	if err := startProfiling(); err != nil {
+		log.Fatalf("Unable to start profiling: %s, err: %s", options.cpuProfile, err)
+	}
+	defer stopProfiling()
+
+	// check-permissions reports missing permissions itself, instead of exiting at the preflight check
+	if !options.noPreflight && !offlineSubcommands.contains(flag.Arg(0)) && flag.Arg(0) != "check-permissions" {
+		if err := preflight(ctx); err != nil {
+			log.Fatalf("Preflight check failed, err: %s", err)
+		}
+	}
+
+	if options.lock != "" {
+		if options.lockTTL <= 0 {
+			log.Fatalf("Invalid lock-ttl: %s, expected a positive duration", options.lockTTL)
+		}
+		lock, err := newRunLock(options.lock)
+		if err != nil {
+			log.Fatalf("Invalid lock: %s, err: %s", options.lock, err)
+		}
+		if err := acquireRunLock(lock, options.lockTTL, options.lockWait); err != nil {
+			log.Fatalf("Unable to acquire lock: %s, err: %s", options.lock, err)
+		}
+		// fatal errors from here on exit through fatalf, releasing the lock
+		holdRunLock(lock, options.lockTTL)
+		defer releaseRunLock()
+	}
+
+	if flag.NArg() > 0 {
+		run, found := subcommands[flag.Arg(0)]
+		if !found {
+			fatalf("Unknown subcommand: %s", flag.Arg(0))
+		}
+		run(ctx, flag.Args()[1:])
+		return
+	}
+
+	outputs, err := parseOutputs()
 	if err != nil {
-		log.Printf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+		fatalf("Invalid output, err: %s", err)
+	}
+	for _, o := range outputs {
+		if o[1] != "" {
+			log.Printf("SLO report file will be saved at: %s \n", o[1])
+		}
 	}
 
-	log.Printf("Getting SLO History for %d SLOs ...", len(slos))
-	generateReport(slos)
-	log.Printf("Done - History retrived for %d SLOs", len(slos))
-}
-
-// creates a csv file and for each slo, adds slo status / error budget consumed details
-func generateReport(slos []datadog.ServiceLevelObjective) {
-	cols := []string{
-		"name",
-		"slo_id",
-		"timeframe",
-		"from (utc)",
-		"to (utc)",
-		"from_ts",
-		"to_ts",
-		"target",
-		"overall_status",
-		"error_budget_consumed",
-		"error (only if applicable)",
-	}
-	// create file
-	file, err := os.Create(options.filePath)
+	source, err := newSLOSource()
 	if err != nil {
-		log.Fatalf("Unable to create file: %s, err: %s", options.filePath, err)
+		fatalf("Invalid slo source: %s, err: %s", options.sloSource, err)
+	}
+	log.Printf("Getting SLO History while listing SLOs ...")
+	total := generateReport(ctx, streamSLOs(ctx, source, options.limit), outputs)
+	if ctx.Err() != nil {
+		stopProfiling()
+		fatalf("Run stopped after %d SLOs, err: %s", total, ctx.Err())
+	}
+	if options.errorPolicy.stop() {
+		stopProfiling()
+		fatalf("Run stopped after %d api errors (-error-policy %s)", atomic.LoadInt64(&apiErrors), options.errorPolicy.String())
+	}
+	log.Printf("Done - History retrived for %d SLOs", total)
+}
+
+// reportWriter writes report records, the first record written is the header
+type reportWriter interface {
+	Write(record []string) error
+	Flush()
+}
+
+// opens the report outputs (e.g a csv file and a terminal table) and for each slo, adds slo status / error budget consumed details
+func generateReport(ctx context.Context, slos *sloStream, outputSpecs [][2]string) int {
+	var opened []*output
+	var writers multiWriter
+	// the manifest, rollup and integrity evidence are written next to the first report file
+	reportPath := ""
+	for _, spec := range outputSpecs {
+		o, err := outputFormats[spec[0]](spec[1])
+		if err != nil {
+			fatalf("Unable to create file: %s, err: %s", spec[1], err)
+		}
+		opened = append(opened, o)
+		writers = append(writers, o.writer)
+		if reportPath == "" {
+			reportPath = o.path
+		}
 	}
 
-	defer file.Close()
-	writer := csv.NewWriter(file)
+	var writer reportWriter = writers
+	if len(writers) == 1 {
+		writer = writers[0]
+	}
+	var rowCommand *execWriter
+	if options.execRow != "" {
+		w, err := newExecWriter(writer, options.execRow)
+		if err != nil {
+			fatalf("Unable to start command: %s, err: %s", options.execRow, err)
+		}
+		rowCommand = w
+		writer = w
+	}
+	if options.kafkaURL != "" {
+		writer = newKafkaWriter(writer, options.kafkaURL, options.kafkaTopic)
+	}
+	if telemetry != nil {
+		writer = &otelWriter{next: writer, exporter: telemetry}
+	}
+	var rollup *rollupWriter
+	if config.Hierarchy != nil {
+		rollup = newRollupWriter(writer)
+		writer = rollup
+	}
+	var notebook *notebookWriter
+	if options.notebook != "" {
+		notebook = newNotebookWriter(writer)
+		writer = notebook
+	}
+	var teams *teamWriter
+	if options.teamReports {
+		teams = newTeamWriter(writer)
+		writer = teams
+	}
+	counts := &countingWriter{next: writer}
+	writer = counts
+	if rowFilter != nil {
+		writer = &filterWriter{next: writer, filter: rowFilter}
+	}
+	var policy *policyWriter
+	if len(config.BudgetPolicy) > 0 {
+		policy = newPolicyWriter(writer, config.BudgetPolicy)
+		writer = policy
+	}
+	if len(config.DerivedColumns) > 0 {
+		writer = &derivedWriter{next: writer, columns: config.DerivedColumns}
+	}
+	var composites *compositeWriter
+	if len(config.CompositeSLOs) > 0 {
+		composites = newCompositeWriter(writer, config.CompositeSLOs)
+		writer = composites
+	}
 	defer writer.Flush()
-	if err := writer.Write(cols); err != nil {
-		log.Fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+	if err := writer.Write(rowColumns()); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
 	}
 
-	ctx := datadog.NewDefaultContext(context.Background())
-	configuration := datadog.NewConfiguration()
-	configuration.SetUnstableOperationEnabled("GetSLOHistory", true)
-	apiClient := datadog.NewAPIClient(configuration)
-	now := time.Now().UTC()
-	totalSlos := len(slos)
-	for counter, slo := range slos {
+	if options.exportDir != "" {
+		if err := os.MkdirAll(options.exportDir, 0755); err != nil {
+			fatalf("Unable to create directory: %s, err: %s", options.exportDir, err)
+		}
+	}
+
+	apiClient := newAPIClient()
+	if options.downtimes {
+		downtimes, err := loadMonitorDowntimes(ctx, apiClient)
+		if err != nil {
+			fatalf("Error when calling `DowntimesApi.ListDowntimes`: %v\n", err)
+		}
+		monitorDowntimes = downtimes
+	}
+	if options.muteStatus {
+		muted, err := loadMutedMonitors(ctx, apiClient)
+		if err != nil {
+			fatalf("Error when calling `MonitorsApi.ListMonitors`: %v\n", err)
+		}
+		mutedMonitors = muted
+	}
+	if options.incidents {
+		loaded, err := loadIncidents(ctx)
+		if err != nil {
+			fatalf("Unable to load datadog incidents, err: %s", err)
+		}
+		orgIncidents = loaded
+	}
+	now := runClock.Now().UTC()
+	var definitions []string
+	var incidents []activeIncident
+	historyCalls = newHistoryCoalescer()
+	for counter := 0; ; counter++ {
+		if options.errorPolicy.stop() || ctx.Err() != nil {
+			break
+		}
+		slo, ok := <-slos.slos
+		if !ok {
+			break
+		}
+		// duplicate history calls are coalesced within an slo
+		historyCalls.reset()
+		atomic.AddInt64(&processedSLOs, 1)
+		// slos are still being listed, the total is the number listed so far
+		totalSlos := slos.count()
+		if options.exportDir != "" {
+			path, err := exportDefinition(options.exportDir, slo)
+			if err != nil {
+				fatalf("Unable to write to file: %s, err: %s", path, err)
+			}
+			definitions = append(definitions, path)
+		}
+		override := sloOverrides.forSLO(slo)
+		if override.Skip {
+			log.Printf("(%d of %d) Skipping s: %s, skipped by overrides", counter+1, totalSlos, slo.GetId())
+			continue
+		}
+		slo = withTargetOverride(slo)
+		if teams != nil {
+			teams.addSLO(slo)
+		}
+		if options.fastBurn && len(slo.Thresholds) > 0 {
+			// only the slo being reported is kept
+			slis, errs := loadShortWindowSLIs(ctx, apiClient, slo, now)
+			shortWindowSLIs = map[string][]*float64{slo.GetId(): slis}
+			shortWindowErrs = map[string][]error{slo.GetId(): errs}
+			// incidents are evaluated against the first configured target
+			rates, fast := burnRates(slis, slo.Thresholds[0].Target)
+			switch {
+			case fast:
+				log.Printf("Active incident s: %s, fast error budget burn", slo.GetId())
+				incidents = append(incidents, activeIncident{slo: slo, target: slo.Thresholds[0].Target, burnRates: rates})
+			case firstError(errs) != nil:
+				log.Printf("Unable to evaluate fast burn s: %s, err: %s", slo.GetId(), firstError(errs))
+			}
+		}
+		if options.forecast {
+			// only the slo being reported is kept
+			monthForecasts = map[string]*sloForecast{slo.GetId(): forecastMonth(ctx, apiClient, slo, now)}
+		}
+		if options.requireTeam {
+			if tag, _, found := sloTeam(slo); !found {
+				log.Printf("(%d of %d) Skipping s: %s, err: unknown team: %q", counter+1, totalSlos, slo.GetId(), tag)
+				for _, threshold := range slo.Thresholds {
+					row := reportRow{slo: slo, threshold: threshold}
+					if err := writeErr(writer, row, fmt.Errorf("unknown team: %q", tag)); err != nil {
+						fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+					}
+				}
+				continue
+			}
+		}
+		if options.weeks > 0 {
+			log.Printf("(%d of %d) Getting weekly SLO history s: %s", counter+1, totalSlos, slo.GetId())
+			if len(slo.Thresholds) == 0 {
+				log.Printf("Unable to get weekly history s: %s, err: slo has no thresholds", slo.GetId())
+				continue
+			}
+			// weekly rollups are evaluated against the first configured target
+			row := reportRow{slo: slo, threshold: slo.Thresholds[0]}
+			reportSpans(ctx, splitWeekly(row, options.weeks, now), func(week reportRow) {
+				reportTimeSpan(ctx, apiClient, writer, week)
+			})
+			continue
+		}
+
+		// the timeframes and windows of the slo are fetched concurrently, their rows written in order
+		var windows []func(reportWriter)
 		for _, threshold := range slo.Thresholds {
+			if len(options.timeframes) > 0 && !options.timeframes.contains(string(threshold.Timeframe)) {
+				continue
+			}
+			if options.excludeTimeframes.contains(string(threshold.Timeframe)) {
+				continue
+			}
 			log.Printf("(%d of %d) Getting SLO history s: %s, tf: %s", counter+1, totalSlos, slo.GetId(), threshold.Timeframe)
 			from, to, err := getSLOTimeSpanFromTimeframe(threshold.Timeframe, now)
+			row := reportRow{slo: slo, threshold: threshold, from: from, to: to}
 			// track and write error
 			if err != nil {
 				log.Printf(
 					"Unable to get time span from timeframe s: %s, tf: %s, err: %s",
 					slo.GetId(), threshold.Timeframe, err,
 				)
-				err := writeErr(writer, slo, threshold, from, to, err)
-				if err != nil {
-					log.Fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
-				}
+				windows = append(windows, func(w reportWriter) {
+					if err := writeErr(w, row, err); err != nil {
+						fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+					}
+				})
 				continue
 			}
 
-			// get slo history
-			history, err := getSLOHistory(ctx, apiClient, slo, threshold, from, to)
-			if err != nil {
-				log.Printf(
-					"Unable to get slo history s: %s, tf: %s, err: %s",
-					slo.GetId(), threshold.GetTimeframe(), err,
-				)
-				err := writeErr(writer, slo, threshold, from, to, err)
-				if err != nil {
-					log.Fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
-				}
-				continue
+			windows = append(windows, func(w reportWriter) {
+				reportWindow(ctx, apiClient, w, row)
+			})
+		}
+
+		// additional windows are evaluated against the first configured target
+		for _, window := range append(append(windowList{}, options.windows...), override.Windows...) {
+			if len(slo.Thresholds) == 0 {
+				break
 			}
+			log.Printf("(%d of %d) Getting SLO history s: %s, tf: %s", counter+1, totalSlos, slo.GetId(), window)
+			row := reportRow{
+				slo:       slo,
+				threshold: slo.Thresholds[0],
+				timeframe: window.String(),
+				from:      now.Add(-window.duration()),
+				to:        now,
+			}
+			windows = append(windows, func(w reportWriter) {
+				reportWindow(ctx, apiClient, w, row)
+			})
+		}
+		reportConcurrently(ctx, writer, windows, options.timeframeConcurrency)
+		if err := sleepContext(ctx, options.sleep); err != nil {
+			break
+		}
+	}
 
-			// write history to file
-			err = writeHistory(writer, slo, threshold, *history, from, to)
-			if err != nil {
-				log.Printf(
-					"Unable to write slo history details s: %s, tf: %s, err: %s",
-					slo.GetId(), threshold.Timeframe, err,
-				)
-				err := writeErr(writer, slo, threshold, from, to, err)
-				if err != nil {
-					log.Fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
-				}
-				continue
+	if err := slos.stop(); err != nil && ctx.Err() == nil {
+		recordAPIError()
+		log.Printf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	totalSlos := slos.count()
+	summary.SLOsListed = totalSlos
+
+	if composites != nil {
+		if err := composites.writeComposites(writer, config.CompositeSLOs); err != nil {
+			fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+		}
+	}
+
+	if policy != nil {
+		policy.logSummary()
+	}
+	if options.fastBurn {
+		log.Printf("%d SLOs with active incidents (fast error budget burn)", len(incidents))
+	}
+
+	if len(counts.deletedSLOs) > 0 {
+		log.Printf("%d SLOs were deleted during the run: %s", len(counts.deletedSLOs), strings.Join(counts.deletedSLOs, ", "))
+	}
+
+	if err := telemetry.exportIfEnabled(); err != nil {
+		log.Printf("Unable to export telemetry to: %s, err: %s", options.otlpEndpoint, err)
+	}
+
+	writer.Flush()
+	summary.finish(counts.rows, options.summaryPath)
+	if notebook != nil {
+		if url, err := notebook.createNotebook(ctx, options.notebook, totalSlos, counts.rows); err != nil {
+			log.Printf("Unable to create notebook: %s, err: %s", options.notebook, err)
+		} else {
+			log.Printf("Review notebook created: %s", url)
+		}
+	}
+	if teams != nil {
+		teams.notifyTeams()
+	}
+	for _, o := range opened {
+		if err := o.close(); err != nil {
+			fatalf("Unable to write to file: %s, err: %s", o.path, err)
+		}
+	}
+	if rowCommand != nil {
+		if err := rowCommand.close(); err != nil {
+			log.Printf("Command failed: %s, err: %s", options.execRow, err)
+		}
+	}
+	if reportPath == "" {
+		notifyRunCompleted(newManifest("", totalSlos, counts), "")
+		return totalSlos
+	}
+
+	m := newManifest(reportPath, totalSlos, counts)
+	if err := writeManifest(m); err != nil {
+		log.Printf("Unable to write manifest: %s, err: %s", manifestPath(reportPath), err)
+	}
+	artifacts := []string{manifestPath(reportPath)}
+	for _, o := range opened {
+		if o.path != "" {
+			artifacts = append(artifacts, o.path)
+		}
+	}
+	if rollup != nil {
+		if err := rollup.writeRollup(rollupPath(reportPath)); err != nil {
+			log.Printf("Unable to write rollup: %s, err: %s", rollupPath(reportPath), err)
+		} else {
+			artifacts = append(artifacts, rollupPath(reportPath))
+		}
+	}
+	if teams != nil {
+		paths, err := teams.writeTeamReports(reportPath)
+		if err != nil {
+			log.Printf("Unable to write team reports, err: %s", err)
+		}
+		artifacts = append(artifacts, paths...)
+	}
+	if options.fastBurn {
+		if err := writeIncidents(incidentsPath(reportPath), incidents); err != nil {
+			log.Printf("Unable to write active incidents: %s, err: %s", incidentsPath(reportPath), err)
+		} else {
+			artifacts = append(artifacts, incidentsPath(reportPath))
+		}
+	}
+	artifacts = append(artifacts, definitions...)
+	writeIntegrityEvidence(reportPath, artifacts)
+	notifyRunCompleted(m, manifestPath(reportPath))
+	return totalSlos
+}
+
+// notifyRunCompleted runs the -exec-after command and publishes the run summary to sns / sqs, if configured
+func notifyRunCompleted(m manifest, manifestFile string) {
+	if options.execAfter != "" {
+		if err := runExecAfter(m, manifestFile); err != nil {
+			log.Printf("Command failed: %s, err: %s", options.execAfter, err)
+		}
+	}
+	if options.snsTopicARN == "" && options.sqsQueueURL == "" {
+		return
+	}
+	if options.notifyOn == "failure" && !runFailed(m) {
+		log.Printf("Run succeeded, not notified with -notify-on failure")
+		return
+	}
+	if err := publishRunNotification(m, manifestFile); err != nil {
+		log.Printf("Unable to publish run notification, err: %s", err)
+		return
+	}
+	log.Printf("Run notification published")
+}
+
+// createReportFile creates the report file, encrypted if enabled
+func createReportFile(path string) (io.WriteCloser, error) {
+	if options.encryptWith == "" {
+		return os.Create(path)
+	}
+	return createEncrypted(path, options.encryptWith, options.recipients)
+}
+
+// writeReportFile writes content to path, or encrypted to its encrypted path if enabled, returning the path written
+func writeReportFile(path string, content []byte) (string, error) {
+	if options.encryptWith != "" {
+		path = encryptedPath(path, options.encryptWith)
+	}
+	file, err := createReportFile(path)
+	if err != nil {
+		return path, err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return path, err
+	}
+	return path, file.Close()
+}
+
+// writeIntegrityEvidence writes the checksums of the report files and signs them, if enabled
+func writeIntegrityEvidence(reportPath string, paths []string) {
+	if !options.checksum && options.signWith == "" {
+		return
+	}
+	sumPath, err := writeChecksums(reportPath, paths)
+	if err != nil {
+		fatalf("Unable to write checksums: %s, err: %s", checksumPath(reportPath), err)
+	}
+	log.Printf("Checksums written to: %s", sumPath)
+	if options.signWith == "" {
+		return
+	}
+	sigPath, err := signFile(sumPath, options.signWith, options.signKey)
+	if err != nil {
+		fatalf("Unable to sign checksums: %s, err: %s", sumPath, err)
+	}
+	log.Printf("Checksums signature written to: %s", sigPath)
+}
+
+// reportWindow reports the row time span, split into days in daily mode
+func reportWindow(ctx context.Context, apiClient *datadog.APIClient, writer reportWriter, row reportRow) {
+	spans := []reportRow{row}
+	if options.daily {
+		spans = splitDaily(row)
+	}
+	reportSpans(ctx, spans, func(span reportRow) {
+		reportTimeSpan(ctx, apiClient, writer, span)
+	})
+}
+
+// reportSpans reports each time span with report, pausing -sleep after each, the remaining spans are skipped once the
+// run is stopped during a pause
+func reportSpans(ctx context.Context, spans []reportRow, report func(reportRow)) {
+	for _, span := range spans {
+		report(span)
+		if err := sleepContext(ctx, options.sleep); err != nil {
+			return
+		}
+	}
+}
+
+// reportTimeSpan gets the slo history for the row time span and writes it, or the error, to the report
+func reportTimeSpan(ctx context.Context, apiClient *datadog.APIClient, writer reportWriter, row reportRow) {
+	slo, threshold := row.slo, row.threshold
+	// get slo history
+	start := runClock.Now()
+	history, err := getChunkedSLOHistory(ctx, apiClient, slo, threshold, row.from, row.to)
+	if err != nil && ctx.Err() != nil {
+		// the run was stopped, the window is left out of the report
+		return
+	}
+	summary.recordHistoryCall(err)
+	telemetry.recordSpan("GetSLOHistory", start, map[string]string{
+		"slo_id":    slo.GetId(),
+		"timeframe": row.timeframeLabel(),
+		"period":    row.period,
+	}, err)
+	if err != nil {
+		log.Printf(
+			"Unable to get slo history s: %s, tf: %s, err: %s",
+			slo.GetId(), threshold.GetTimeframe(), err,
+		)
+		if rawResponseEnabled() {
+			var rawErr error
+			if row.raw, rawErr = rawError(row, err); rawErr != nil {
+				log.Printf("Unable to save raw slo history error s: %s, tf: %s, err: %s", slo.GetId(), threshold.GetTimeframe(), rawErr)
 			}
-			writer.Flush()
-			time.Sleep(options.sleep)
 		}
-		time.Sleep(options.sleep)
+		// prefixed so api errors are distinguishable from unsupported timeframes in the report
+		if err != errSLODeleted {
+			recordAPIError()
+			err = fmt.Errorf("api error: %w", err)
+		}
+		err := writeErr(writer, row, err)
+		if err != nil {
+			fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+		}
+		return
+	}
+
+	if options.previousPeriod {
+		row.previous = loadPreviousPeriod(ctx, apiClient, row)
+	}
+
+	if rawResponseEnabled() {
+		row.raw, err = rawHistory(row, *history)
+		if err != nil {
+			log.Printf("Unable to save raw slo history s: %s, tf: %s, err: %s", slo.GetId(), threshold.GetTimeframe(), err)
+		}
+	}
+
+	// write history to file
+	err = writeHistory(writer, row, *history)
+	if err != nil {
+		log.Printf(
+			"Unable to write slo history details s: %s, tf: %s, err: %s",
+			slo.GetId(), threshold.Timeframe, err,
+		)
+		err := writeErr(writer, row, err)
+		if err != nil {
+			fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+		}
+		return
+	}
+	writer.Flush()
+}
+
+// newAPIClient returns a datadog api client with the unstable operations used by this script enabled, and those of
+// the config unstable_operations
+func newAPIClient() *datadog.APIClient {
+	configuration := datadog.NewConfiguration()
+	configuration.UserAgent = userAgent()
+	for operation, enabled := range unstableOperations() {
+		configuration.SetUnstableOperationEnabled(operation, enabled)
+	}
+	return datadog.NewAPIClient(configuration)
+}
+
+// reportColumns are the report csv columns, in the order written by reportRow.values
+var reportColumns = schema.Columns
+
+// reportRow holds the details written to the report for a slo timeframe, or one of its groups or periods
+type reportRow struct {
+	slo                 datadog.ServiceLevelObjective
+	threshold           datadog.SLOThreshold
+	timeframe           string
+	group               string
+	period              string
+	from, to            time.Time
+	sliValue            *float64
+	errorBudgetConsumed *float64
+	// groupTarget is the group's own target the group row is evaluated against, if configured
+	groupTarget *float64
+	// goodEvents and totalEvents are the event counts of metric slos, with -event-counts
+	goodEvents  *float64
+	totalEvents *float64
+	// noData is set for windows without data, the sli and error budget consumed are unset
+	noData bool
+	// previous is the window before the row's, with -previous-period
+	previous *previousPeriod
+	err      error
+	raw      string
+}
+
+// schemaRow returns the row without its optional columns
+func (r reportRow) schemaRow() schema.ReportRow {
+	errStr, errType := "", ""
+	if r.err != nil {
+		errStr, errType = r.err.Error(), errorType(r.err)
+	}
+	return schema.ReportRow{
+		Name:                r.slo.GetName(),
+		SLOID:               r.slo.GetId(),
+		Timeframe:           r.timeframeLabel(),
+		Group:               r.group,
+		Period:              r.period,
+		From:                r.from,
+		To:                  r.to,
+		Target:              r.threshold.GetTarget(),
+		Warning:             r.threshold.Warning,
+		SLI:                 r.sliValue,
+		ErrorBudgetConsumed: r.errorBudgetConsumed,
+		Status:              r.status(),
+		Error:               errStr,
+		ErrorType:           errType,
+	}
+}
+
+// values returns the row values in rowColumns order
+func (r reportRow) values() []string {
+	values := r.schemaRow().Record()
+	if options.resolveTeams {
+		_, team, _ := sloTeam(r.slo)
+		values = append(values, team.Attributes.Name, team.Attributes.Handle)
+	}
+	if config.Hierarchy != nil {
+		values = append(values, sloHierarchy(r.slo)...)
+	}
+	for _, key := range options.tagColumns {
+		values = append(values, sloTagValue(r.slo, key))
+	}
+	if sloOverrides != nil {
+		labels := sloOverrides.forSLO(r.slo).Labels
+		for _, key := range sloOverrides.labelKeys {
+			values = append(values, labels[key])
+		}
+	}
+	if len(config.GroupTargets) > 0 {
+		values = append(values, formatOptionalFloat(r.groupTarget))
+	}
+	if options.eventCounts {
+		values = append(values, formatOptionalFloat(r.goodEvents), formatOptionalFloat(r.totalEvents))
+	}
+	if options.downtimes {
+		values = append(values, formatOptionalFloat(downtimeCoverage(r.slo, r.from, r.to)))
+	}
+	if options.muteStatus {
+		values = append(values, r.muteValues()...)
+	}
+	if options.incidents {
+		values = append(values, sloIncidents(r.slo, r.from, r.to))
+	}
+	if contractTargets != nil {
+		values = append(values, r.slaValues()...)
+	}
+	if options.fastBurn {
+		values = append(values, r.burnRateValues()...)
+	}
+	if options.forecast {
+		values = append(values, r.forecastValues()...)
+	}
+	if baselines != nil {
+		values = append(values, r.anomalyValues()...)
+	}
+	if options.previousPeriod {
+		values = append(values, r.previousValues()...)
+	}
+	if rawResponseEnabled() {
+		values = append(values, r.raw)
+	}
+	return values
+}
+
+// status classifications of the sli against the warning and target thresholds, see the schema package
+const (
+	StatusOK       = schema.StatusOK
+	StatusWarning  = schema.StatusWarning
+	StatusBreached = schema.StatusBreached
+	StatusDeleted  = schema.StatusDeleted
+	StatusNoData   = schema.StatusNoData
+)
+
+// errSLODeleted is returned for the history of an slo deleted since it was listed
+var errSLODeleted = withErrorType(schema.ErrorNotFound, errors.New("slo not found, deleted since it was listed"))
+
+// status returns the sli classification, empty when there is no sli
+func (r reportRow) status() string {
+	if r.err == errSLODeleted {
+		return StatusDeleted
+	}
+	if r.noData {
+		switch options.noData {
+		case "pass":
+			return StatusOK
+		case "fail":
+			return StatusBreached
+		}
+		return StatusNoData
 	}
+	if r.sliValue == nil {
+		return ""
+	}
+	if r.groupTarget != nil {
+		if *r.sliValue < *r.groupTarget {
+			return StatusBreached
+		}
+		return StatusOK
+	}
+	if *r.sliValue < r.threshold.GetTarget() {
+		return StatusBreached
+	}
+	if warning, ok := r.threshold.GetWarningOk(); ok && *r.sliValue < *warning {
+		return StatusWarning
+	}
+	return StatusOK
+}
+
+// timeframeLabel returns the row timeframe, defaulting to the threshold timeframe
+func (r reportRow) timeframeLabel() string {
+	if r.timeframe != "" {
+		return r.timeframe
+	}
+	return string(r.threshold.GetTimeframe())
+}
+
+// formatOptionalFloat formats f, returning an empty string when it is not set
+func formatOptionalFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return fmt.Sprintf("%f", *f)
 }
 
-// getSLOHistory returns slo history
+// getSLOHistory returns slo history, the same history requested again during a report is fetched once
 func getSLOHistory(
 	ctx context.Context,
 	apiClient *datadog.APIClient,
 	slo datadog.ServiceLevelObjective,
 	threshold datadog.SLOThreshold,
 	from, to time.Time,
+) (*datadog.SLOHistoryResponse, error) {
+	return historyCalls.do(newHistoryKey(slo, threshold, from, to), func() (*datadog.SLOHistoryResponse, error) {
+		return fetchSLOHistory(ctx, apiClient, slo, threshold, from, to)
+	})
+}
+
+// fetchSLOHistory calls the slo history api
+func fetchSLOHistory(
+	ctx context.Context,
+	apiClient *datadog.APIClient,
+	slo datadog.ServiceLevelObjective,
+	threshold datadog.SLOThreshold,
+	from, to time.Time,
 ) (*datadog.SLOHistoryResponse, error) {
 	optionalParams := datadog.GetSLOHistoryOptionalParameters{
 		Target: &threshold.Target,
 	}
-	resp, _, err := apiClient.ServiceLevelObjectivesApi.GetSLOHistory(
+	resp, httpResp, err := apiClient.ServiceLevelObjectivesApi.GetSLOHistory(
 		ctx,
 		slo.GetId(),
 		from.UTC().Unix(),
@@ -166,7 +1169,10 @@ func getSLOHistory(
 		optionalParams,
 	)
 	if err != nil {
-		return nil, err
+		if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+			return nil, errSLODeleted
+		}
+		return nil, classifyAPIError(err, httpResp)
 	}
 
 	// check for top level errors
@@ -175,27 +1181,27 @@ func getSLOHistory(
 		for _, err := range *respErrors {
 			errStr := err.GetError()
 			if errStr != "" {
-				return nil, errors.New(errStr)
+				return nil, withErrorType(schema.ErrorAPI, errors.New(errStr))
 			}
 		}
 	}
 
 	// make sure data is not nil
 	if resp.Data == nil {
-		return nil, errors.New("no history data received")
+		return nil, withErrorType(schema.ErrorNoData, errors.New("no history data received"))
 	}
 
 	overallResp := resp.Data.Overall
 	// make sure overall data is not nil
 	if overallResp == nil {
-		return nil, errors.New("no overall history received")
+		return nil, withErrorType(schema.ErrorNoData, errors.New("no overall history received"))
 	}
 
 	// check overall response errors
 	if overallResp.Errors != nil {
 		for _, overallErr := range *overallResp.Errors {
 			if overallErr.ErrorMessage != "" {
-				return nil, errors.New(overallErr.ErrorMessage)
+				return nil, withErrorType(schema.ErrorAPI, errors.New(overallErr.ErrorMessage))
 			}
 		}
 	}
@@ -203,106 +1209,204 @@ func getSLOHistory(
 	return &resp, nil
 }
 
-// writeHistory write slo history to csv file
-func writeHistory(
-	writer *csv.Writer,
-	slo datadog.ServiceLevelObjective,
-	threshold datadog.SLOThreshold,
-	history datadog.SLOHistoryResponse,
-	from, to time.Time,
-) error {
-	overall := *history.Data.Overall
-	errorBudgetRemainingMap := overall.GetErrorBudgetRemaining()
+// writeHistory write slo history to csv file, the overall row is followed by a row
+// for each group matching the group-by dimension (if set)
+func writeHistory(writer reportWriter, row reportRow, history datadog.SLOHistoryResponse) error {
+	overallRow, err := newHistoryRow(row, *history.Data.Overall)
+	if err != nil {
+		return err
+	}
+	if options.eventCounts {
+		overallRow = withEventCounts(overallRow, history)
+	}
+	// metric slos without events in the window have no data rather than a 0 or 100% sli
+	if series, ok := history.Data.GetSeriesOk(); ok && series.Denominator.Sum == 0 {
+		overallRow.noData, overallRow.sliValue, overallRow.errorBudgetConsumed = true, nil, nil
+	}
+	if err := writer.Write(overallRow.values()); err != nil {
+		return err
+	}
+	if options.groupBy == "" {
+		return nil
+	}
+
+	for _, groupData := range history.Data.GetGroups() {
+		group := groupData.GetGroup()
+		if group == "" {
+			group = groupData.GetName()
+		}
+		if !groupHasDimension(group, options.groupBy) {
+			continue
+		}
+		groupRow := row
+		groupRow.group = group
+		groupRow, err := newHistoryRow(groupRow, groupData)
+		if err != nil {
+			log.Printf("Unable to get group history s: %s, tf: %s, g: %s, err: %s", row.slo.GetId(), row.threshold.GetTimeframe(), group, err)
+			groupRow.err = err
+		}
+		groupRow = withGroupTarget(groupRow)
+		if err := writer.Write(groupRow.values()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newHistoryRow returns row populated with the overall or group sli data
+func newHistoryRow(row reportRow, sliData datadog.SLOHistorySLIData) (reportRow, error) {
+	if _, ok := sliData.GetSliValueOk(); !ok {
+		row.noData = true
+		return row, nil
+	}
+
+	errorBudgetRemainingMap := sliData.GetErrorBudgetRemaining()
 	// use custom since from/to is passed
 	errorBudgetRemaining, found := errorBudgetRemainingMap["custom"]
 	if !found {
-		log.Printf("Unable to get error budget remaining s: %s, tf: %s", slo.GetId(), threshold.GetTimeframe())
-		return errors.New("unable to get errror budget remaining")
+		log.Printf("Unable to get error budget remaining s: %s, tf: %s", row.slo.GetId(), row.threshold.GetTimeframe())
+		return row, withErrorType(schema.ErrorNoData, errors.New("unable to get errror budget remaining"))
 	}
 
-	data := []string{
-		slo.GetName(),
-		slo.GetId(),
-		string(threshold.GetTimeframe()),
-		fmt.Sprintf("%s", from.UTC()),
-		fmt.Sprintf("%s", to.UTC()),
-		fmt.Sprintf("%d", from.UTC().Unix()),
-		fmt.Sprintf("%d", to.UTC().Unix()),
-		fmt.Sprintf("%f", threshold.GetTarget()),
-		fmt.Sprintf("%f", overall.GetSliValue()),
-		fmt.Sprintf("%f", 100.0-errorBudgetRemaining),
-		"",
+	errorBudgetConsumed := 100.0 - errorBudgetRemaining
+	row.sliValue, _ = sliData.GetSliValueOk()
+	row.errorBudgetConsumed = &errorBudgetConsumed
+	return row, nil
+}
+
+// groupHasDimension returns true when the group (e.g. datacenter:us1,env:prod) has a value for the dimension
+func groupHasDimension(group, dimension string) bool {
+	for _, tag := range strings.Split(group, ",") {
+		if strings.HasPrefix(strings.TrimSpace(tag), dimension+":") {
+			return true
+		}
 	}
-	return writer.Write(data)
+	return false
 }
 
 // writeErr write error to csv file
-func writeErr(
-	writer *csv.Writer,
-	slo datadog.ServiceLevelObjective,
-	threshold datadog.SLOThreshold,
-	from, to time.Time,
-	err error,
-) error {
-	data := []string{
-		slo.GetName(),
-		slo.GetId(),
-		string(threshold.GetTimeframe()),
-		fmt.Sprintf("%s", from.UTC()),
-		fmt.Sprintf("%s", to.UTC()),
-		fmt.Sprintf("%d", from.UTC().Unix()),
-		fmt.Sprintf("%d", to.UTC().Unix()),
-		fmt.Sprintf("%f", threshold.GetTarget()),
-		"",
-		"",
-		err.Error(),
-	}
-	return writer.Write(data)
-}
-
-// getAllSLOs returns all slos
-func getAllSLOs(limit int64, tagQuery string) ([]datadog.ServiceLevelObjective, error) {
-	ctx := datadog.NewDefaultContext(context.Background())
+func writeErr(writer reportWriter, row reportRow, err error) error {
+	row.err = err
+	return writer.Write(row.values())
+}
+
+// getAllSLOs returns all slos matching the tag query, or the -query search when set, with recovered thresholds and normalized tags
+func getAllSLOs(ctx context.Context, limit int64, tagQuery string) ([]datadog.ServiceLevelObjective, error) {
+	allSLOs, err := getSLODefinitions(ctx, limit, tagQuery)
+	if err != nil {
+		return allSLOs, err
+	}
+
+	recoverThresholds(allSLOs)
+	if config.TagNormalization != nil {
+		normalizeSLOTags(allSLOs, config.TagNormalization)
+	}
+	return allSLOs, nil
+}
+
+// getSLODefinitions returns all slos matching the tag query, or the -query search when set, as defined in datadog
+// i.e without tag normalization, for subcommands writing slos back
+func getSLODefinitions(ctx context.Context, limit int64, tagQuery string) ([]datadog.ServiceLevelObjective, error) {
+	var allSLOs []datadog.ServiceLevelObjective
+	var err error
+	if options.query != "" {
+		allSLOs, err = searchSLOs(ctx, options.query)
+	} else {
+		allSLOs, err = listOrgSLOs(ctx, limit, tagQuery)
+	}
+	if err != nil {
+		return allSLOs, err
+	}
+	return limitSLOs(allSLOs), nil
+}
+
+// limitSLOs returns the slos in the -shard, a -sample random fraction of them, capped at -max-slos
+func limitSLOs(slos []datadog.ServiceLevelObjective) []datadog.ServiceLevelObjective {
+	filter := &sloFilter{}
+	var selected []datadog.ServiceLevelObjective
+	for _, slo := range slos {
+		keep, more := filter.keep(slo)
+		if keep {
+			selected = append(selected, slo)
+		}
+		if !more {
+			break
+		}
+	}
+	if len(selected) < len(slos) {
+		log.Printf("Processing %d of %d SLOs", len(selected), len(slos))
+	}
+	return selected
+}
+
+// listOrgSLOs returns all slos matching the tag query of the org the context has credentials for
+func listOrgSLOs(ctx context.Context, limit int64, tagQuery string) ([]datadog.ServiceLevelObjective, error) {
+	var allSLOs []datadog.ServiceLevelObjective
+	err := listOrgSLOPages(ctx, limit, tagQuery, func(page []datadog.ServiceLevelObjective) error {
+		allSLOs = append(allSLOs, page...)
+		return nil
+	})
+	if err != nil {
+		return []datadog.ServiceLevelObjective{}, err
+	}
+	return allSLOs, nil
+}
+
+// listOrgSLOPages calls fn with each page of slos matching the tag query of the org the context has credentials for,
+// stopping at the first error fn returns
+func listOrgSLOPages(ctx context.Context, limit int64, tagQuery string, fn sloPageFunc) error {
 	offset := int64(0)
-	configuration := datadog.NewConfiguration()
-	apiClient := datadog.NewAPIClient(configuration)
+	apiClient := newAPIClient()
 	optionalParams := datadog.ListSLOsOptionalParameters{
 		Limit:     &limit,
 		Offset:    &offset,
 		TagsQuery: &tagQuery,
 	}
 
-	var allSLOs []datadog.ServiceLevelObjective
 	if tagQuery != "" {
 		log.Printf("Querying SLOs for tag %s", tagQuery)
 	}
 
-	resp, _, err := apiClient.ServiceLevelObjectivesApi.ListSLOs(ctx, optionalParams)
-	if err != nil {
-		return []datadog.ServiceLevelObjective{}, err
-	}
-	slos := *resp.Data
-	allSLOs = append(allSLOs, slos...)
-
-	loaded := int64(len(*resp.Data))
-	total := *resp.Metadata.Page.TotalCount
-	log.Printf("Loaded %d SLOs, total SLOs %d \n", loaded, total)
-	// load all slos
-	for loaded < total {
-		offset += loaded
-		optionalParams.Offset = &offset
-		resp, _, err := apiClient.ServiceLevelObjectivesApi.ListSLOs(ctx, optionalParams)
+	// slos created or deleted while listing shift the pages, so slos may be listed twice or missed
+	seen := map[string]bool{}
+	drift := listDrift{InitialTotal: -1}
+	for {
+		resp, httpResp, err := listSLOPage(ctx, apiClient, optionalParams)
 		if err != nil {
-			return []datadog.ServiceLevelObjective{}, err
+			return err
+		}
+		metadata := resp.GetMetadata()
+		total := metadata.Page.GetTotalCount()
+		if drift.InitialTotal < 0 {
+			drift.InitialTotal = total
+		}
+		var page []datadog.ServiceLevelObjective
+		for _, slo := range resp.GetData() {
+			if seen[slo.GetId()] {
+				log.Printf("SLO listed twice s: %s, skipping the duplicate", slo.GetId())
+				drift.Duplicates++
+				continue
+			}
+			seen[slo.GetId()] = true
+			page = append(page, slo)
+		}
+		log.Printf("Loaded %d SLOs, total SLOs %d \n", len(seen), total)
+		if err := fn(page); err != nil {
+			return err
+		}
+		offset += int64(len(resp.GetData()))
+		if len(resp.GetData()) == 0 || offset >= total {
+			break
+		}
+		optionalParams.Offset = &offset
+		// the next page is paced by the rate limit headers of this one
+		if err := sleepContext(ctx, pageDelay(httpResp)); err != nil {
+			return err
 		}
-		slos = *resp.Data
-		allSLOs = append(allSLOs, slos...)
-		loaded += int64(len(*resp.Data))
-		log.Printf("Loaded %d SLOs, total SLOs %d \n", loaded, total)
-		time.Sleep(1 * time.Second)
 	}
-
-	return allSLOs, nil
+	drift.Listed = int64(len(seen))
+	validateListedCount(ctx, apiClient, tagQuery, drift)
+	return nil
 }
 
 // getSLOTimeSpanFromTimeframe returns from/to time based on the slo timeframe
@@ -316,5 +1420,10 @@ func getSLOTimeSpanFromTimeframe(tf datadog.SLOTimeframe, now time.Time) (time.T
 		return now.Add(-NinetyDays), now, nil
 	}
 
-	return time.Time{}, time.Time{}, fmt.Errorf("unsupported SLO timeframe : %s", tf)
+	// other rolling timeframes in days e.g 1d, custom timeframes have no rolling window
+	var days window
+	if err := days.Set(string(tf)); err == nil {
+		return now.Add(-days.duration()), now, nil
+	}
+	return time.Time{}, time.Time{}, withErrorType(schema.ErrorUnsupported, fmt.Errorf("unsupported timeframe: %s", tf))
 }
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mergeKey identifies a report row across partial reports, the run is the utc day the row's window ends
+type mergeKey struct {
+	sloID, timeframe, group, period, run string
+}
+
+// runMerge concatenates partial reports (shards, orgs) with the same columns into one report, rows reported by
+// several of them are written once
+func runMerge(_ context.Context, args []string) {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	output := fs.String("o", "", "path of the merged csv report")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s merge REPORT.csv ... -o MERGED.csv\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	paths := parseInterspersed(fs, args)
+	if len(paths) == 0 || *output == "" {
+		fs.Usage()
+		exit(2)
+	}
+
+	header, rows, err := mergeReports(paths)
+	if err != nil {
+		fatalf("Unable to merge reports, err: %s", err)
+	}
+	if err := writeMergedReport(*output, header, rows); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", *output, err)
+	}
+	log.Printf("Merged %d reports into %d rows written to: %s", len(paths), len(rows), *output)
+}
+
+// mergeReports returns the common header of the csv reports and their rows in order, a row reported several times
+// is kept once preferring the row without an error then the latest window
+func mergeReports(paths []string) ([]string, [][]string, error) {
+	var header []string
+	var rows [][]string
+	index := map[mergeKey]int{}
+	for _, path := range paths {
+		records, err := readReportCSV(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", path, err)
+		}
+		if len(records) == 0 {
+			return nil, nil, fmt.Errorf("%s: empty report", path)
+		}
+		if header == nil {
+			header = records[0]
+			columns := recordLookup(header, header)
+			for _, col := range []string{"slo_id", "timeframe", "to_ts", "error"} {
+				if _, found := columns(col); !found {
+					return nil, nil, fmt.Errorf("%s: missing %s column", path, col)
+				}
+			}
+		} else if err := sameColumns(header, records[0]); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", path, err)
+		}
+
+		duplicates := 0
+		for _, record := range records[1:] {
+			key, err := rowMergeKey(header, record)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", path, err)
+			}
+			i, found := index[key]
+			if !found {
+				index[key] = len(rows)
+				rows = append(rows, record)
+				continue
+			}
+			duplicates++
+			if preferRow(header, record, rows[i]) {
+				rows[i] = record
+			}
+		}
+		log.Printf("Read %d rows from: %s (%d already reported)", len(records)-1, path, duplicates)
+	}
+	return header, rows, nil
+}
+
+// readReportCSV returns the records of the csv report, without the utf-8 byte order mark of -bom reports
+func readReportCSV(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], utf8BOM)
+	}
+	return records, nil
+}
+
+// sameColumns returns an error naming the first difference when the headers don't have the same columns in order
+func sameColumns(want, got []string) error {
+	for i := 0; i < len(want) || i < len(got); i++ {
+		switch {
+		case i >= len(got):
+			return fmt.Errorf("schema mismatch: missing column %s", want[i])
+		case i >= len(want):
+			return fmt.Errorf("schema mismatch: unexpected column %s", got[i])
+		case want[i] != got[i]:
+			return fmt.Errorf("schema mismatch: column %d is %s, expected %s", i+1, got[i], want[i])
+		}
+	}
+	return nil
+}
+
+// rowMergeKey returns the key identifying the record across reports
+func rowMergeKey(header, record []string) (mergeKey, error) {
+	lookup := recordLookup(header, record)
+	id, _ := lookup("slo_id")
+	timeframe, _ := lookup("timeframe")
+	group, _ := lookup("group")
+	period, _ := lookup("period")
+	to, err := rowEnd(lookup)
+	if err != nil {
+		return mergeKey{}, fmt.Errorf("slo %s: %s", id, err)
+	}
+	return mergeKey{id, timeframe, group, period, to.Format("2006-01-02")}, nil
+}
+
+// rowEnd returns the end of the record's window from its to_ts column
+func rowEnd(lookup fieldLookup) (time.Time, error) {
+	value, _ := lookup("to_ts")
+	ts, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid to_ts: %s", value)
+	}
+	return time.Unix(ts, 0).UTC(), nil
+}
+
+// preferRow returns true when record should replace kept, a row without an error wins over an error row,
+// otherwise the row with the latest window
+func preferRow(header, record, kept []string) bool {
+	lookup, keptLookup := recordLookup(header, record), recordLookup(header, kept)
+	errStr, _ := lookup("error")
+	keptErr, _ := keptLookup("error")
+	if (errStr == "") != (keptErr == "") {
+		return errStr == ""
+	}
+	to, _ := rowEnd(lookup)
+	keptTo, _ := rowEnd(keptLookup)
+	return to.After(keptTo)
+}
+
+// writeMergedReport writes the header and rows to the csv file
+func writeMergedReport(path string, header []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer, err := newCSVWriter(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Write(header); err != nil {
+		file.Close()
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// monthlyColumns are the columns of the customer facing monthly sla report
+var monthlyColumns = []string{
+	"SLO",
+	"Month",
+	"Target (%)",
+	"Attainment (%)",
+	"Status",
+}
+
+// runMonthly writes each slo's attainment for a full calendar month (the previous month by default),
+// flagging slos below their target
+func runMonthly(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("monthly", flag.ContinueOnError)
+	month := fs.String("month", "", "calendar month to report e.g 2021-08 (default previous month)")
+	parseFlags(fs, args)
+
+	from := startOfMonth(runClock.Now().UTC()).AddDate(0, -1, 0)
+	if *month != "" {
+		parsed, err := time.Parse("2006-01", *month)
+		if err != nil {
+			fatalf("Invalid month: %s, expected YYYY-MM, err: %s", *month, err)
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 1, 0)
+	log.Printf("Monthly SLA report for %s will be saved at: %s \n", from.Format("January 2006"), options.filePath)
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+
+	file, err := os.Create(options.filePath)
+	if err != nil {
+		fatalf("Unable to create file: %s, err: %s", options.filePath, err)
+	}
+	defer file.Close()
+	writer, err := newCSVWriter(file)
+	if err != nil {
+		fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+	}
+	defer writer.Flush()
+	if err := writer.Write(monthlyColumns); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+	}
+
+	apiClient := newAPIClient()
+	below := 0
+	for counter, slo := range slos {
+		slo = withTargetOverride(slo)
+		if len(slo.Thresholds) == 0 {
+			log.Printf("Skipping s: %s, err: slo has no thresholds", slo.GetId())
+			continue
+		}
+		// monthly attainment is evaluated against the first configured target
+		threshold := slo.Thresholds[0]
+		log.Printf("(%d of %d) Getting monthly SLO history s: %s", counter+1, len(slos), slo.GetId())
+		data := []string{
+			slo.GetName(),
+			from.Format("January 2006"),
+			fmt.Sprintf("%.3f", threshold.GetTarget()),
+		}
+
+		history, err := getSLOHistory(ctx, apiClient, slo, threshold, from, to)
+		if err != nil {
+			// customer facing reports don't include raw api errors, only log them
+			log.Printf("Unable to get slo history s: %s, err: %s", slo.GetId(), err)
+			data = append(data, "", "Unavailable")
+		} else if sli, ok := history.Data.Overall.GetSliValueOk(); !ok {
+			// months without data are not below target
+			data = append(data, "", "No data")
+		} else {
+			status := "Met"
+			if *sli < threshold.GetTarget() {
+				status = "Below target"
+				below++
+			}
+			data = append(data, fmt.Sprintf("%.3f", *sli), status)
+		}
+
+		if err := writer.Write(data); err != nil {
+			fatalf("Unable to write to file: %s, err: %s", options.filePath, err)
+		}
+		if err := sleepContext(ctx, options.sleep); err != nil {
+			writer.Flush()
+			fatalf("Monthly report stopped after %d of %d SLOs, err: %s", counter+1, len(slos), err)
+		}
+	}
+	log.Printf("Done - %d of %d SLOs below target for %s", below, len(slos), from.Format("January 2006"))
+}
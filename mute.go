@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// muteColumns are the mute status columns added with -mute-status: the hours of the window covered by downtimes of
+// the slo's monitors, how many of them are muted now, and whether the row looks good while they were in downtime or
+// are muted now, mute history isn't available so the last two show the current state
+var muteColumns = []string{"downtime_hours", "muted_monitors_now", "masked_by_mute_now"}
+
+// mutedMonitors are the ids of the monitors muted now, loaded once per run with -mute-status
+var mutedMonitors map[int64]bool
+
+// loadMutedMonitors returns the ids of the monitors with a scope muted now, through their silenced option, mutes
+// without an end last until unmuted
+func loadMutedMonitors(ctx context.Context, apiClient *datadog.APIClient) (map[int64]bool, error) {
+	monitors, err := listAllMonitors(ctx, apiClient, "")
+	if err != nil {
+		return nil, err
+	}
+	now := runClock.Now()
+	muted := map[int64]bool{}
+	for _, monitor := range monitors {
+		for _, end := range monitor.Options.GetSilenced() {
+			if end == 0 || time.Unix(end, 0).After(now) {
+				muted[monitor.GetId()] = true
+			}
+		}
+	}
+	return muted, nil
+}
+
+// muteValues returns the row's mute status column values, unset for slos without monitors (e.g metric slos): rows
+// are masked by mutes when they are OK or WARNING while downtimes covered part of the window or a monitor is muted now
+func (r reportRow) muteValues() []string {
+	covered, found := downtimeCovered(r.slo, r.from, r.to)
+	if !found {
+		return make([]string, len(muteColumns))
+	}
+	muted := 0
+	for _, id := range r.slo.GetMonitorIds() {
+		if mutedMonitors[id] {
+			muted++
+		}
+	}
+	status := r.status()
+	masked := (status == StatusOK || status == StatusWarning) && (covered > 0 || muted > 0)
+	hours := covered.Hours()
+	return []string{formatOptionalFloat(&hours), fmt.Sprintf("%d", muted), fmt.Sprintf("%t", masked)}
+}
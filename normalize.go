@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// tagNormalization rewrites slo tags before they are used for filtering and columns,
+// e.g mapping squad: and owner: to team:, lowercasing values and aliasing old team names
+type tagNormalization struct {
+	// KeyAliases maps tag keys to the key they are rewritten to e.g {"squad": "team"}
+	KeyAliases map[string]string `json:"key_aliases"`
+	// LowercaseValues lowercases every tag value
+	LowercaseValues bool `json:"lowercase_values"`
+	// ValueAliases maps, per (rewritten) tag key, values to the value they are rewritten to e.g {"team": {"ninjas": "ninja"}}
+	ValueAliases map[string]map[string]string `json:"value_aliases"`
+}
+
+// normalizeTag returns the normalized key:value tag, split on the first colon with the spaces around the key and
+// value trimmed, tags without a value only have their key aliased
+func (n *tagNormalization) normalizeTag(tag string) string {
+	parts := strings.SplitN(tag, ":", 2)
+	key := strings.TrimSpace(parts[0])
+	if alias, found := n.KeyAliases[key]; found {
+		key = alias
+	}
+	if len(parts) == 1 {
+		return key
+	}
+	value := strings.TrimSpace(parts[1])
+	if n.LowercaseValues {
+		value = strings.ToLower(value)
+	}
+	if alias, found := n.ValueAliases[key][value]; found {
+		value = alias
+	}
+	return key + ":" + value
+}
+
+// normalizeSLOTags normalizes the tags of each slo in place, dropping tags that become duplicates
+func normalizeSLOTags(slos []datadog.ServiceLevelObjective, n *tagNormalization) {
+	for i := range slos {
+		tags := slos[i].GetTags()
+		if len(tags) == 0 {
+			continue
+		}
+		seen := map[string]bool{}
+		normalized := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			tag = n.normalizeTag(tag)
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+		slos[i].SetTags(normalized)
+	}
+}
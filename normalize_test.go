@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	n := &tagNormalization{
+		KeyAliases:      map[string]string{"squad": "team", "owner": "team"},
+		LowercaseValues: true,
+		ValueAliases:    map[string]map[string]string{"team": {"ninjas": "ninja"}},
+	}
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{"unchanged", "env:prod", "env:prod"},
+		{"key alias", "squad:ninja", "team:ninja"},
+		{"lowercased value", "team:Ninja", "team:ninja"},
+		{"value alias after lowercasing", "owner:NINJAS", "team:ninja"},
+		{"key case is kept", "Squad:ninja", "Squad:ninja"},
+		{"spaces around key and value", " squad : Ninjas ", "team:ninja"},
+		{"colon in value", "url:https://example.com", "url:https://example.com"},
+		{"empty value", "squad:", "team:"},
+		{"no value", "squad", "team"},
+		{"no value with spaces", " critical ", "critical"},
+		{"empty key", ":ninja", ":ninja"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := n.normalizeTag(tt.tag); got != tt.want {
+				t.Errorf("normalizeTag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSLOTags(t *testing.T) {
+	n := &tagNormalization{KeyAliases: map[string]string{"squad": "team"}, LowercaseValues: true}
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"no tags", nil, nil},
+		{"duplicates after normalization dropped", []string{"team:Ninja", "squad:ninja", "env:prod"}, []string{"team:ninja", "env:prod"}},
+		{"order kept", []string{"env:prod", "squad:a", "team:b"}, []string{"env:prod", "team:a", "team:b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slos := []datadog.ServiceLevelObjective{{}}
+			if tt.tags != nil {
+				slos[0].SetTags(tt.tags)
+			}
+			normalizeSLOTags(slos, n)
+			if got := slos[0].GetTags(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeSLOTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// notebookWorstSLOs is the number of slos with the most error budget consumed listed in the review notebook
+const notebookWorstSLOs = 10
+
+// notebookRow is an slo timeframe row of the review notebook
+type notebookRow struct {
+	name, id, timeframe, status string
+	target, sli, consumed       string
+	consumedValue               float64
+}
+
+// notebookWriter collects the slo timeframe rows for the review notebook before writing them to the next writer
+type notebookWriter struct {
+	next   reportWriter
+	header []string
+	rows   []notebookRow
+	// statuses counts the rows by timeframe and status
+	statuses map[string]map[string]int
+}
+
+// newNotebookWriter returns a notebook writer writing records to next
+func newNotebookWriter(next reportWriter) *notebookWriter {
+	return &notebookWriter{next: next, statuses: map[string]map[string]int{}}
+}
+
+// Write keeps the slo timeframe rows, group and period rows are left out
+func (w *notebookWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		return w.next.Write(record)
+	}
+	lookup := recordLookup(w.header, record)
+	group, _ := lookup("group")
+	period, _ := lookup("period")
+	if group != "" || period != "" {
+		return w.next.Write(record)
+	}
+	row := notebookRow{}
+	row.name, _ = lookup("name")
+	row.id, _ = lookup("slo_id")
+	row.timeframe, _ = lookup("timeframe")
+	row.status, _ = lookup("status")
+	row.target, _ = lookup("target")
+	row.sli, _ = lookup("overall_status")
+	row.consumed, _ = lookup("error_budget_consumed")
+	if errStr, _ := lookup("error"); errStr != "" && row.status == "" {
+		row.status = "ERROR"
+	}
+	if w.statuses[row.timeframe] == nil {
+		w.statuses[row.timeframe] = map[string]int{}
+	}
+	w.statuses[row.timeframe][row.status]++
+	if consumed, err := lookupNumber(lookup, "error_budget_consumed"); err == nil {
+		row.consumedValue = consumed
+		w.rows = append(w.rows, row)
+	}
+	return w.next.Write(record)
+}
+
+// Flush flushes the next writer
+func (w *notebookWriter) Flush() {
+	w.next.Flush()
+}
+
+// markdownCell returns a notebook markdown cell
+func markdownCell(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "notebook_cells",
+		"attributes": map[string]interface{}{"definition": map[string]interface{}{"type": "markdown", "text": text}},
+	}
+}
+
+// cells returns the notebook cells: the run summary, the rows by timeframe and status and the slos with the most
+// error budget consumed linked to their slo page
+func (w *notebookWriter) cells(name string, slos, rows int) []interface{} {
+	s := summary.snapshot()
+	header := fmt.Sprintf("# %s\n\n%s: %d SLOs, %d rows, %d of %d history calls failed.",
+		name, startedAt.UTC().Format("2006-01-02 15:04 MST"), slos, rows, s.Failed, s.HistoryCalls)
+
+	statuses := []string{StatusOK, StatusWarning, StatusBreached, StatusNoData, StatusDeleted, "ERROR"}
+	var table strings.Builder
+	table.WriteString("## Status by timeframe\n\n| timeframe | " + strings.Join(statuses, " | ") + " |\n|---|" + strings.Repeat("---|", len(statuses)) + "\n")
+	timeframes := make([]string, 0, len(w.statuses))
+	for tf := range w.statuses {
+		timeframes = append(timeframes, tf)
+	}
+	sort.Strings(timeframes)
+	for _, tf := range timeframes {
+		table.WriteString("| " + tf + " |")
+		for _, status := range statuses {
+			fmt.Fprintf(&table, " %d |", w.statuses[tf][status])
+		}
+		table.WriteString("\n")
+	}
+
+	worst := append([]notebookRow{}, w.rows...)
+	sort.SliceStable(worst, func(i, j int) bool { return worst[i].consumedValue > worst[j].consumedValue })
+	if len(worst) > notebookWorstSLOs {
+		worst = worst[:notebookWorstSLOs]
+	}
+	var list strings.Builder
+	fmt.Fprintf(&list, "## Most error budget consumed\n\n| SLO | timeframe | target | SLI | error budget consumed | status |\n|---|---|---|---|---|---|\n")
+	for _, row := range worst {
+		fmt.Fprintf(&list, "| [%s](https://app.%s/slo?slo_id=%s) | %s | %s | %s | %s | %s |\n",
+			strings.NewReplacer("|", "\\|", "[", "\\[", "]", "\\]").Replace(row.name), datadogSite(), row.id,
+			row.timeframe, row.target, row.sli, row.consumed, row.status)
+	}
+	return []interface{}{markdownCell(header), markdownCell(table.String()), markdownCell(list.String())}
+}
+
+// createNotebook creates the review notebook of the run with the notebooks api, returning its url
+func (w *notebookWriter) createNotebook(ctx context.Context, name string, slos, rows int) (string, error) {
+	fullName := fmt.Sprintf("%s %s", name, startedAt.UTC().Format("2006-01-02"))
+	body := map[string]interface{}{
+		"data": map[string]interface{}{
+			"type": "notebooks",
+			"attributes": map[string]interface{}{
+				"name":   fullName,
+				"status": "published",
+				"time":   map[string]string{"live_span": "1w"},
+				"cells":  w.cells(fullName, slos, rows),
+			},
+		},
+	}
+	var resp struct {
+		Data struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	if err := datadogSend(ctx, http.MethodPost, "/api/v1/notebooks", nil, body, &resp); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://app.%s/notebook/%d", datadogSite(), resp.Data.ID), nil
+}
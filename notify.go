@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// runNotification is published to sns / sqs when a run completes
+type runNotification struct {
+	// Event is slo_report_completed, or slo_report_failed when the run failed
+	Event string `json:"event"`
+	// Manifest is the manifest path, empty when no report file was written
+	Manifest string `json:"manifest,omitempty"`
+	manifest
+}
+
+// publishRunNotification publishes the run summary to the configured sns topic and sqs queue
+func publishRunNotification(m manifest, manifestFile string) error {
+	event := "slo_report_completed"
+	if runFailed(m) {
+		event = "slo_report_failed"
+	}
+	content, err := json.Marshal(runNotification{Event: event, Manifest: manifestFile, manifest: m})
+	if err != nil {
+		return err
+	}
+	if options.snsTopicARN != "" {
+		if err := publishSNS(options.snsTopicARN, string(content)); err != nil {
+			return err
+		}
+	}
+	if options.sqsQueueURL != "" {
+		if err := sendSQS(options.sqsQueueURL, string(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runFailed returns true when the run was stopped by the error policy or has more error rows than -notify-max-errors
+func runFailed(m manifest) bool {
+	return options.errorPolicy.stop() || m.ErrorRows > options.notifyMaxErrors
+}
+
+// publishSNS publishes the message to the topic, the region is taken from the arn
+func publishSNS(topicARN, message string) error {
+	// arn:aws:sns:region:account:name
+	parts := strings.Split(topicARN, ":")
+	if len(parts) != 6 || parts[2] != "sns" {
+		return fmt.Errorf("invalid sns topic arn: %s", topicARN)
+	}
+	region := parts[3]
+	form := url.Values{
+		"Action":   {"Publish"},
+		"Version":  {"2010-03-31"},
+		"TopicArn": {topicARN},
+		"Message":  {message},
+	}
+	_, err := awsQuery("sns", region, fmt.Sprintf("https://sns.%s.amazonaws.com/", region), form)
+	return err
+}
+
+// sendSQS sends the message to the queue, the region is taken from the queue url
+func sendSQS(queueURL, message string) error {
+	// https://sqs.region.amazonaws.com/account/name
+	u, err := url.Parse(queueURL)
+	if err != nil {
+		return err
+	}
+	hostParts := strings.Split(u.Host, ".")
+	if len(hostParts) < 3 || hostParts[0] != "sqs" {
+		return fmt.Errorf("invalid sqs queue url: %s", queueURL)
+	}
+	form := url.Values{
+		"Action":      {"SendMessage"},
+		"Version":     {"2012-11-05"},
+		"MessageBody": {message},
+	}
+	_, err = awsQuery("sqs", hostParts[1], queueURL, form)
+	return err
+}
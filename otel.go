@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// telemetry exports report metrics and run traces over otlp/http json, nil when disabled
+var telemetry *otelExporter
+
+// otelExporter collects gauges from report rows and spans for history calls, exported at the end of the run
+type otelExporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+
+	mu         sync.Mutex
+	traceID    string
+	rootSpanID string
+	started    time.Time
+	spans      []otlpSpan
+	sli        []otlpDataPoint
+	budget     []otlpDataPoint
+}
+
+// otlp json payload types, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpKeyValue struct {
+	Key   string            `json:"key"`
+	Value map[string]string `json:"value"`
+}
+
+type otlpDataPoint struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	AsDouble     float64        `json:"asDouble"`
+	Attributes   []otlpKeyValue `json:"attributes"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// newOtelExporter returns an exporter for the collector endpoint e.g http://localhost:4318,
+// OTEL_EXPORTER_OTLP_HEADERS (key=value,...) are sent with each export
+func newOtelExporter(endpoint string) *otelExporter {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+			headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return &otelExporter{
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		headers:    headers,
+		client:     &http.Client{Timeout: 30 * time.Second, Transport: userAgentTransport{}},
+		traceID:    randomHex(16),
+		rootSpanID: randomHex(8),
+		started:    runClock.Now(),
+	}
+}
+
+// recordSpan records a history call span under the run span, nil safe
+func (o *otelExporter) recordSpan(name string, start time.Time, attrs map[string]string, err error) {
+	if o == nil {
+		return
+	}
+	span := otlpSpan{
+		TraceID:           o.traceID,
+		SpanID:            randomHex(8),
+		ParentSpanID:      o.rootSpanID,
+		Name:              name,
+		Kind:              3, // client
+		StartTimeUnixNano: unixNano(start),
+		EndTimeUnixNano:   unixNano(runClock.Now()),
+		Attributes:        otlpAttributes(attrs),
+	}
+	if err != nil {
+		span.Status = &otlpStatus{Code: 2, Message: err.Error()} // error
+	}
+	o.mu.Lock()
+	o.spans = append(o.spans, span)
+	o.mu.Unlock()
+}
+
+// recordRow records the sli and error budget gauges of a report row
+func (o *otelExporter) recordRow(row map[string]string) {
+	attrs := otlpAttributes(map[string]string{
+		"slo_id":    row["slo_id"],
+		"slo_name":  row["name"],
+		"timeframe": row["timeframe"],
+		"group":     row["group"],
+		"period":    row["period"],
+	})
+	now := unixNano(runClock.Now())
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if sli, err := strconv.ParseFloat(row["overall_status"], 64); err == nil {
+		o.sli = append(o.sli, otlpDataPoint{TimeUnixNano: now, AsDouble: sli, Attributes: attrs})
+	}
+	if budget, err := strconv.ParseFloat(row["error_budget_consumed"], 64); err == nil {
+		o.budget = append(o.budget, otlpDataPoint{TimeUnixNano: now, AsDouble: budget, Attributes: attrs})
+	}
+}
+
+// exportIfEnabled exports the telemetry, nil safe
+func (o *otelExporter) exportIfEnabled() error {
+	if o == nil {
+		return nil
+	}
+	return o.export()
+}
+
+// export sends the collected metrics and the run trace to the collector
+func (o *otelExporter) export() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	resource := map[string]interface{}{
+		"attributes": otlpAttributes(map[string]string{"service.name": "slo-report", "service.version": version}),
+	}
+	scope := map[string]string{"name": "slos", "version": version}
+
+	metrics := map[string]interface{}{
+		"resourceMetrics": []interface{}{map[string]interface{}{
+			"resource": resource,
+			"scopeMetrics": []interface{}{map[string]interface{}{
+				"scope": scope,
+				"metrics": []interface{}{
+					map[string]interface{}{"name": "slo.sli", "unit": "%", "gauge": map[string]interface{}{"dataPoints": o.sli}},
+					map[string]interface{}{"name": "slo.error_budget_consumed", "unit": "%", "gauge": map[string]interface{}{"dataPoints": o.budget}},
+				},
+			}},
+		}},
+	}
+	if err := o.post("/v1/metrics", metrics); err != nil {
+		return err
+	}
+
+	root := otlpSpan{
+		TraceID:           o.traceID,
+		SpanID:            o.rootSpanID,
+		Name:              "slo_report",
+		Kind:              1, // internal
+		StartTimeUnixNano: unixNano(o.started),
+		EndTimeUnixNano:   unixNano(runClock.Now()),
+		Attributes:        otlpAttributes(map[string]string{"tag_query": options.tagQuery}),
+	}
+	traces := map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": resource,
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": scope,
+				"spans": append([]otlpSpan{root}, o.spans...),
+			}},
+		}},
+	}
+	return o.post("/v1/traces", traces)
+}
+
+// post sends the json payload to the collector path
+func (o *otelExporter) post(path string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, o.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range o.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s", path, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// otelWriter records each record's gauges with the exporter before writing it to the next writer
+type otelWriter struct {
+	next     reportWriter
+	exporter *otelExporter
+	header   []string
+}
+
+// Write records the row metrics and writes the record to the next writer
+func (w *otelWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+	} else {
+		w.exporter.recordRow(recordMap(w.header, record))
+	}
+	return w.next.Write(record)
+}
+
+// Flush flushes the next writer
+func (w *otelWriter) Flush() {
+	w.next.Flush()
+}
+
+// otlpAttributes returns string attributes sorted by key, empty values are skipped
+func otlpAttributes(attrs map[string]string) []otlpKeyValue {
+	var kvs []otlpKeyValue
+	for key, value := range attrs {
+		if value != "" {
+			kvs = append(kvs, otlpKeyValue{Key: key, Value: map[string]string{"stringValue": value}})
+		}
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+	return kvs
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// randomHex returns n random bytes hex encoded, used for trace and span ids
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
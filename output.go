@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// output is an opened report output, its writer receives the report records (the first being the header)
+type output struct {
+	format string
+	// path is empty for outputs written to the terminal
+	path   string
+	writer reportWriter
+	// close completes the output once all records are written e.g closing the file or rendering the table
+	close func() error
+}
+
+// outputFormats are the registered report output formats, opening an output for a path
+var outputFormats = map[string]func(path string) (*output, error){
+	"csv":     openCSVOutput,
+	"github":  openGitHubOutput,
+	"gitlab":  openGitLabOutput,
+	"json":    openJSONOutput,
+	"junit":   openJUnitOutput,
+	"table":   openTableOutput,
+	"parquet": openParquetOutput,
+	"xlsx":    openXLSXOutput,
+}
+
+// stdoutFormats are the output formats printed to stdout instead of written to a path
+var stdoutFormats = map[string]bool{"table": true, "github": true}
+
+// outputFormatNames returns the registered output formats, sorted
+func outputFormatNames() []string {
+	names := make([]string, 0, len(outputFormats))
+	for name := range outputFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultReportPath returns slo_report.csv in the os temp directory e.g /tmp/slo_report.csv, or
+// %TEMP%\slo_report.csv on windows
+func defaultReportPath() string {
+	return filepath.Join(os.TempDir(), "slo_report.csv")
+}
+
+// parseOutputs returns the format and path of each -output e.g csv:/tmp/slo_report.csv,
+// defaulting to the -format written to -path
+func parseOutputs() ([][2]string, error) {
+	if len(options.outputs) == 0 {
+		if _, found := outputFormats[options.format]; !found {
+			return nil, fmt.Errorf("unsupported format: %s, expected one of %s", options.format, strings.Join(outputFormatNames(), ", "))
+		}
+		if stdoutFormats[options.format] {
+			return [][2]string{{options.format, ""}}, nil
+		}
+		return [][2]string{{options.format, options.filePath}}, nil
+	}
+	var outputs [][2]string
+	for _, value := range options.outputs {
+		parts := strings.SplitN(value, ":", 2)
+		if _, found := outputFormats[parts[0]]; !found {
+			return nil, fmt.Errorf("unsupported format: %s, expected one of %s", parts[0], strings.Join(outputFormatNames(), ", "))
+		}
+		path := ""
+		if len(parts) == 2 {
+			path = parts[1]
+		}
+		if path == "" && !stdoutFormats[parts[0]] {
+			return nil, fmt.Errorf("output %s has no path, expected FORMAT:PATH", value)
+		}
+		if options.encryptWith != "" && !stdoutFormats[parts[0]] {
+			path = encryptedPath(path, options.encryptWith)
+		}
+		outputs = append(outputs, [2]string{parts[0], path})
+	}
+	return outputs, nil
+}
+
+// openCSVOutput creates the csv report file, encrypted if enabled, column renames and locale formatting are applied
+func openCSVOutput(path string) (*output, error) {
+	file, err := createReportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := newCSVWriter(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	var w reportWriter = writer
+	if formatEnabled() {
+		writer.format = newFormatWriter(w, len(config.ColumnNames) > 0)
+		w = writer.format
+	}
+	return &output{format: "csv", path: path, writer: w, close: func() error {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}}, nil
+}
+
+// openTableOutput returns a terminal table output, locale formatting is applied
+func openTableOutput(string) (*output, error) {
+	table := newTableWriter(os.Stdout)
+	var w reportWriter = table
+	if formatEnabled() {
+		// table columns are selected by name, so they are not renamed
+		w = newFormatWriter(w, false)
+	}
+	return &output{format: "table", writer: w, close: func() error {
+		table.Render()
+		return nil
+	}}, nil
+}
+
+// openJSONOutput creates a json lines report file, encrypted if enabled, with a json object per row
+func openJSONOutput(path string) (*output, error) {
+	file, err := createReportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := &jsonWriter{out: file}
+	return &output{format: "json", path: path, writer: writer, close: func() error {
+		if writer.err != nil {
+			file.Close()
+			return writer.err
+		}
+		return file.Close()
+	}}, nil
+}
+
+// jsonWriter writes each record as a json object keyed by the header columns, one per line
+type jsonWriter struct {
+	out    io.Writer
+	header []string
+	err    error
+}
+
+// Write keeps the header and writes other records as json objects
+func (w *jsonWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		return nil
+	}
+	content, err := json.Marshal(recordMap(w.header, record))
+	if err == nil {
+		_, err = w.out.Write(append(content, '\n'))
+	}
+	if err != nil {
+		w.err = err
+	}
+	return err
+}
+
+// Flush is a no-op, records are written as they come
+func (w *jsonWriter) Flush() {}
+
+// multiWriter writes records to each of its writers
+type multiWriter []reportWriter
+
+// Write writes the record to each writer, stopping at the first error
+func (m multiWriter) Write(record []string) error {
+	for _, w := range m {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush flushes each writer
+func (m multiWriter) Flush() {
+	for _, w := range m {
+		w.Flush()
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOutputs(t *testing.T) {
+	saved := options
+	defer func() { options = saved }()
+	tests := []struct {
+		name        string
+		outputs     stringList
+		format      string
+		encryptWith string
+		want        [][2]string
+		wantErr     bool
+	}{
+		{"default format and path", nil, "csv", "", [][2]string{{"csv", "/tmp/slo_report.csv"}}, false},
+		{"default stdout format", nil, "table", "", [][2]string{{"table", ""}}, false},
+		{"default unsupported format", nil, "pdf", "", nil, true},
+		{"format and path", stringList{"csv:/tmp/a.csv"}, "csv", "", [][2]string{{"csv", "/tmp/a.csv"}}, false},
+		{"windows path", stringList{`csv:C:\x.csv`}, "csv", "", [][2]string{{"csv", `C:\x.csv`}}, false},
+		{"windows path without format", stringList{`C:\x.csv`}, "csv", "", nil, true},
+		{"several outputs", stringList{"csv:/tmp/a.csv", "json:/tmp/a.jsonl", "table"}, "csv", "", [][2]string{
+			{"csv", "/tmp/a.csv"}, {"json", "/tmp/a.jsonl"}, {"table", ""},
+		}, false},
+		{"bare file format", stringList{"csv"}, "csv", "", nil, true},
+		{"empty path", stringList{"json:"}, "csv", "", nil, true},
+		{"bare stdout format", stringList{"github"}, "csv", "", [][2]string{{"github", ""}}, false},
+		{"unsupported format", stringList{"pdf:/tmp/a.pdf"}, "csv", "", nil, true},
+		{"encrypted file outputs", stringList{"csv:/tmp/a.csv", "table"}, "csv", "age", [][2]string{{"csv", "/tmp/a.csv.age"}, {"table", ""}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options.outputs, options.format, options.filePath, options.encryptWith = tt.outputs, tt.format, "/tmp/slo_report.csv", tt.encryptWith
+			got, err := parseOutputs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutputs() = %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOutputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
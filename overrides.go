@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// targetOverrides maps slo ids to what-if targets, loaded from -target-override-file
+var targetOverrides map[string]float64
+
+// loadTargetOverrides loads per slo targets from a json file e.g {"slo_id": 99.95}
+func loadTargetOverrides(path string) (map[string]float64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	overrides := map[string]float64{}
+	if err := json.Unmarshal(content, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// withTargetOverride returns the slo with its threshold targets replaced by the per slo, -overrides or global
+// override target (in that order), history is then evaluated against the override
+func withTargetOverride(slo datadog.ServiceLevelObjective) datadog.ServiceLevelObjective {
+	target, found := targetOverrides[slo.GetId()]
+	if !found {
+		if override := sloOverrides.forSLO(slo); override.Target != nil {
+			target = *override.Target
+		} else {
+			target = options.targetOverride
+		}
+	}
+	if target == 0 {
+		return slo
+	}
+
+	thresholds := make([]datadog.SLOThreshold, len(slo.Thresholds))
+	for i, threshold := range slo.Thresholds {
+		threshold.Target = target
+		threshold.TargetDisplay = nil
+		thresholds[i] = threshold
+	}
+	slo.Thresholds = thresholds
+	return slo
+}
+
+// sloOverride are the options of special case slos set in the -overrides file
+type sloOverride struct {
+	// Skip leaves the slo out of the report
+	Skip bool `json:"skip"`
+	// Target replaces the slo's targets
+	Target *float64 `json:"target"`
+	// Windows are rolling windows evaluated in addition to the slo's timeframes, as -window
+	Windows windowList `json:"windows"`
+	// Labels are written to label_<key> columns
+	Labels map[string]string `json:"labels"`
+}
+
+// overridesFile is the -overrides file, options of slos by id and by tag e.g
+// {"slos": {"slo_id": {"skip": true}}, "tags": {"team:legacy": {"target": 99, "labels": {"owner": "platform"}}}}
+type overridesFile struct {
+	SLOs map[string]sloOverride `json:"slos"`
+	Tags map[string]sloOverride `json:"tags"`
+	// labelKeys are the label keys of all the overrides, sorted, each is a column
+	labelKeys []string
+}
+
+// sloOverrides are loaded from -overrides
+var sloOverrides *overridesFile
+
+// UnmarshalJSON parses windows given as a list of days e.g ["14d", "45d"]
+func (l *windowList) UnmarshalJSON(content []byte) error {
+	var values []string
+	if err := json.Unmarshal(content, &values); err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := l.Set(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadOverrides loads the per slo options of the json file
+func loadOverrides(path string) (*overridesFile, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file overridesFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for _, overrides := range []map[string]sloOverride{file.SLOs, file.Tags} {
+		for name, override := range overrides {
+			if override.Target != nil && (*override.Target <= 0 || *override.Target >= 100) {
+				return nil, fmt.Errorf("%s: invalid target: %v, expected a percentage between 0 and 100", name, *override.Target)
+			}
+			for key := range override.Labels {
+				keys[key] = true
+			}
+		}
+	}
+	for key := range keys {
+		file.labelKeys = append(file.labelKeys, key)
+	}
+	sort.Strings(file.labelKeys)
+	return &file, nil
+}
+
+// forSLO returns the slo's options, the options of its tags (in sorted tag order, later tags win) overridden by the
+// options of its id
+func (f *overridesFile) forSLO(slo datadog.ServiceLevelObjective) sloOverride {
+	merged := sloOverride{Labels: map[string]string{}}
+	if f == nil {
+		return merged
+	}
+	tags := append([]string{}, slo.GetTags()...)
+	sort.Strings(tags)
+	for _, tag := range tags {
+		if override, found := f.Tags[tag]; found {
+			merged = merged.with(override)
+		}
+	}
+	if override, found := f.SLOs[slo.GetId()]; found {
+		merged = merged.with(override)
+	}
+	return merged
+}
+
+// with returns the options with the options set in override replacing them
+func (o sloOverride) with(override sloOverride) sloOverride {
+	o.Skip = o.Skip || override.Skip
+	if override.Target != nil {
+		o.Target = override.Target
+	}
+	if len(override.Windows) > 0 {
+		o.Windows = override.Windows
+	}
+	labels := map[string]string{}
+	for key, value := range o.Labels {
+		labels[key] = value
+	}
+	for key, value := range override.Labels {
+		labels[key] = value
+	}
+	o.Labels = labels
+	return o
+}
+
+// labelColumn returns the column of an -overrides label e.g label_owner
+func labelColumn(key string) string {
+	return "label_" + key
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestForSLO(t *testing.T) {
+	target := func(v float64) *float64 { return &v }
+	overrides := &overridesFile{
+		SLOs: map[string]sloOverride{
+			"abc": {Target: target(99.5), Labels: map[string]string{"owner": "checkout"}},
+			"off": {Skip: true},
+		},
+		Tags: map[string]sloOverride{
+			"team:legacy": {Target: target(99), Windows: windowList{14}, Labels: map[string]string{"owner": "platform", "tier": "2"}},
+			"env:staging": {Target: target(95), Labels: map[string]string{"tier": "3"}},
+		},
+	}
+	slo := func(id string, tags ...string) datadog.ServiceLevelObjective {
+		s := datadog.ServiceLevelObjective{}
+		s.SetId(id)
+		s.SetTags(tags)
+		return s
+	}
+	tests := []struct {
+		name string
+		file *overridesFile
+		slo  datadog.ServiceLevelObjective
+		want sloOverride
+	}{
+		{"no overrides file", nil, slo("abc", "team:legacy"), sloOverride{Labels: map[string]string{}}},
+		{"no match", overrides, slo("xyz", "team:web"), sloOverride{Labels: map[string]string{}}},
+		{"tag", overrides, slo("xyz", "team:legacy"), sloOverride{
+			Target: target(99), Windows: windowList{14}, Labels: map[string]string{"owner": "platform", "tier": "2"},
+		}},
+		{"id overrides tag", overrides, slo("abc", "team:legacy"), sloOverride{
+			Target: target(99.5), Windows: windowList{14}, Labels: map[string]string{"owner": "checkout", "tier": "2"},
+		}},
+		{"later tag in sorted order wins", overrides, slo("xyz", "team:legacy", "env:staging"), sloOverride{
+			Target: target(99), Windows: windowList{14}, Labels: map[string]string{"owner": "platform", "tier": "2"},
+		}},
+		{"skip by id", overrides, slo("off", "team:legacy"), sloOverride{
+			Skip: true, Target: target(99), Windows: windowList{14}, Labels: map[string]string{"owner": "platform", "tier": "2"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.file.forSLO(tt.slo); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("forSLO() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTargetOverride(t *testing.T) {
+	savedFile, savedOverrides, savedTarget := targetOverrides, sloOverrides, options.targetOverride
+	defer func() { targetOverrides, sloOverrides, options.targetOverride = savedFile, savedOverrides, savedTarget }()
+	target := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name      string
+		file      map[string]float64
+		overrides *overridesFile
+		global    float64
+		want      float64
+	}{
+		{"none", nil, nil, 0, 99.9},
+		{"global", nil, nil, 95, 95},
+		{"-overrides tag over global", nil, &overridesFile{Tags: map[string]sloOverride{"team:a": {Target: target(99)}}}, 95, 99},
+		{"-overrides id over tag", nil, &overridesFile{
+			SLOs: map[string]sloOverride{"abc": {Target: target(99.5)}},
+			Tags: map[string]sloOverride{"team:a": {Target: target(99)}},
+		}, 95, 99.5},
+		{"-target-override-file over -overrides and global", map[string]float64{"abc": 99.99}, &overridesFile{
+			SLOs: map[string]sloOverride{"abc": {Target: target(99.5)}},
+			Tags: map[string]sloOverride{"team:a": {Target: target(99)}},
+		}, 95, 99.99},
+		{"-target-override-file of another slo", map[string]float64{"xyz": 99.99}, nil, 95, 95},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetOverrides, sloOverrides, options.targetOverride = tt.file, tt.overrides, tt.global
+			slo := datadog.ServiceLevelObjective{Thresholds: []datadog.SLOThreshold{
+				{Target: 99.9, Timeframe: datadog.SLOTIMEFRAME_SEVEN_DAYS},
+				{Target: 99.9, Timeframe: datadog.SLOTIMEFRAME_THIRTY_DAYS},
+			}}
+			slo.SetId("abc")
+			slo.SetTags([]string{"team:a"})
+			got := withTargetOverride(slo)
+			for _, threshold := range got.Thresholds {
+				if threshold.Target != tt.want {
+					t.Errorf("withTargetOverride() %s target = %v, want %v", threshold.Timeframe, threshold.Target, tt.want)
+				}
+			}
+			if slo.Thresholds[0].Target != 99.9 {
+				t.Errorf("withTargetOverride() changed the slo's thresholds")
+			}
+		})
+	}
+}
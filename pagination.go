@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// defaultPageDelay is the delay between list pages when the response has no rate limit headers
+const defaultPageDelay = 1 * time.Second
+
+// maxPageDelay caps the delay before a list page or its retry
+const maxPageDelay = 2 * time.Minute
+
+// rateLimitReset returns the time until the rate limit period resets from the X-RateLimit-Reset header, in seconds
+func rateLimitReset(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	reset, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Reset"))
+	if err != nil || reset < 0 {
+		return 0, false
+	}
+	return time.Duration(reset) * time.Second, true
+}
+
+// pageDelay returns the delay before the next list page: the time until the rate limit resets spread over the
+// requests remaining in the period, so listing only slows down as the remaining requests run out
+func pageDelay(resp *http.Response) time.Duration {
+	reset, found := rateLimitReset(resp)
+	if !found {
+		return defaultPageDelay
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return defaultPageDelay
+	}
+	delay := reset
+	if remaining > 0 {
+		delay = reset / time.Duration(remaining+1)
+	}
+	if delay > maxPageDelay {
+		return maxPageDelay
+	}
+	return delay
+}
+
+// retryable returns whether a failed list page is retried: rate limited, server errors and network errors are,
+// other client errors e.g 403 are not
+func retryable(resp *http.Response) bool {
+	if resp == nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryDelay returns the delay before retrying a failed list page: until the rate limit resets when rate limited,
+// otherwise an exponential backoff from 1s
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	delay := maxPageDelay
+	// larger shifts overflow
+	if attempt < 30 {
+		delay = time.Second << uint(attempt)
+	}
+	if reset, found := rateLimitReset(resp); found && resp.StatusCode == http.StatusTooManyRequests {
+		delay = reset + time.Second
+	}
+	if delay > maxPageDelay {
+		return maxPageDelay
+	}
+	return delay
+}
+
+// listSLOPage lists a page of slos, retrying failed pages up to -list-retries times
+func listSLOPage(ctx context.Context, apiClient *datadog.APIClient, params datadog.ListSLOsOptionalParameters) (datadog.SLOListResponse, *http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, httpResp, err := apiClient.ServiceLevelObjectivesApi.ListSLOs(ctx, params)
+		if err == nil || attempt >= options.listRetries || !retryable(httpResp) {
+			return resp, httpResp, classifyAPIError(err, httpResp)
+		}
+		delay := retryDelay(httpResp, attempt)
+		log.Printf("Unable to list SLOs offset: %d, retrying in %s, err: %s", *params.Offset, delay, err)
+		summary.recordRetry()
+		if err := sleepContext(ctx, delay); err != nil {
+			return resp, httpResp, err
+		}
+	}
+}
+
+// listDrift compares the slos listed to the total count, which changes when slos are created or deleted while listing
+type listDrift struct {
+	InitialTotal int64 `json:"initial_total"`
+	FinalTotal   int64 `json:"final_total"`
+	Listed       int64 `json:"listed"`
+	Duplicates   int   `json:"duplicates"`
+}
+
+// validateListedCount gets the total count again once listing is done and reports any difference with the slos
+// listed, or duplicates skipped, in the log and the run summary
+func validateListedCount(ctx context.Context, apiClient *datadog.APIClient, tagQuery string, drift listDrift) {
+	limit, offset := int64(1), int64(0)
+	resp, _, err := listSLOPage(ctx, apiClient, datadog.ListSLOsOptionalParameters{Limit: &limit, Offset: &offset, TagsQuery: &tagQuery})
+	if err != nil {
+		log.Printf("Unable to validate the SLO count, err: %s", err)
+		return
+	}
+	metadata := resp.GetMetadata()
+	drift.FinalTotal = metadata.Page.GetTotalCount()
+	if drift.Duplicates == 0 && drift.Listed == drift.FinalTotal && drift.InitialTotal == drift.FinalTotal {
+		return
+	}
+	log.Printf("SLOs changed while listing: %d listed, total count %d when listing started and %d when it ended, %d duplicates skipped",
+		drift.Listed, drift.InitialTotal, drift.FinalTotal, drift.Duplicates)
+	if drift.Listed < drift.FinalTotal {
+		log.Printf("%d SLOs may be missing from the report, run it again to include them", drift.FinalTotal-drift.Listed)
+	}
+	summary.recordListDrift(drift)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// rateLimitedResponse returns a response with the status and the rate limit headers set when not empty
+func rateLimitedResponse(status int, reset, remaining string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	if reset != "" {
+		resp.Header.Set("X-RateLimit-Reset", reset)
+	}
+	if remaining != "" {
+		resp.Header.Set("X-RateLimit-Remaining", remaining)
+	}
+	return resp
+}
+
+func TestPageDelay(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *http.Response
+		want time.Duration
+	}{
+		{"no response", nil, defaultPageDelay},
+		{"no rate limit headers", rateLimitedResponse(http.StatusOK, "", ""), defaultPageDelay},
+		{"missing reset", rateLimitedResponse(http.StatusOK, "", "10"), defaultPageDelay},
+		{"invalid reset", rateLimitedResponse(http.StatusOK, "soon", "10"), defaultPageDelay},
+		{"negative reset", rateLimitedResponse(http.StatusOK, "-5", "10"), defaultPageDelay},
+		{"missing remaining", rateLimitedResponse(http.StatusOK, "10", ""), defaultPageDelay},
+		{"reset spread over the remaining requests", rateLimitedResponse(http.StatusOK, "10", "9"), time.Second},
+		{"plenty of requests remaining", rateLimitedResponse(http.StatusOK, "10", "999"), 10 * time.Millisecond},
+		{"no requests remaining", rateLimitedResponse(http.StatusOK, "30", "0"), 30 * time.Second},
+		{"capped", rateLimitedResponse(http.StatusOK, "3600", "0"), maxPageDelay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageDelay(tt.resp); got != tt.want {
+				t.Errorf("pageDelay() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		attempt int
+		want    time.Duration
+	}{
+		{"network error", nil, 0, time.Second},
+		{"network error backoff", nil, 3, 8 * time.Second},
+		{"server error backoff", rateLimitedResponse(http.StatusBadGateway, "", ""), 2, 4 * time.Second},
+		{"server error ignores the reset", rateLimitedResponse(http.StatusServiceUnavailable, "30", "0"), 1, 2 * time.Second},
+		{"backoff capped", nil, 10, maxPageDelay},
+		{"backoff overflow capped", nil, 40, maxPageDelay},
+		{"rate limited until the reset", rateLimitedResponse(http.StatusTooManyRequests, "30", "0"), 0, 31 * time.Second},
+		{"rate limited reset capped", rateLimitedResponse(http.StatusTooManyRequests, "3600", "0"), 0, maxPageDelay},
+		{"rate limited without reset", rateLimitedResponse(http.StatusTooManyRequests, "", ""), 1, 2 * time.Second},
+		{"rate limited with invalid reset", rateLimitedResponse(http.StatusTooManyRequests, "later", "0"), 2, 4 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDelay(tt.resp, tt.attempt); got != tt.want {
+				t.Errorf("retryDelay() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *http.Response
+		want bool
+	}{
+		{"network error", nil, true},
+		{"rate limited", rateLimitedResponse(http.StatusTooManyRequests, "", ""), true},
+		{"server error", rateLimitedResponse(http.StatusGatewayTimeout, "", ""), true},
+		{"forbidden", rateLimitedResponse(http.StatusForbidden, "", ""), false},
+		{"bad request", rateLimitedResponse(http.StatusBadRequest, "", ""), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.resp); got != tt.want {
+				t.Errorf("retryable() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
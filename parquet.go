@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+)
+
+// parquet physical types, repetitions, encodings and thrift compact protocol types used by the parquet output
+const (
+	parquetDouble    = 5
+	parquetByteArray = 6
+	parquetOptional  = 1
+	parquetUTF8      = 0
+	parquetPlain     = 0
+	parquetRLE       = 3
+	parquetDataPage  = 0
+
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetMagic starts and ends parquet files
+const parquetMagic = "PAR1"
+
+// openParquetOutput creates a parquet report file, encrypted if enabled, with a single row group: numeric columns
+// are optional doubles and the others optional utf8 strings, empty values (and numeric values not parsing as numbers)
+// are nulls, columns are renamed but numbers are not locale formatted
+func openParquetOutput(path string) (*output, error) {
+	file, err := createReportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := &parquetWriter{}
+	return &output{format: "parquet", path: path, writer: writer, close: func() error {
+		if err := writer.writeFile(file); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}}, nil
+}
+
+// parquetWriter keeps the records for the file written once the report is complete
+type parquetWriter struct {
+	header  []string
+	numeric []bool
+	records [][]string
+}
+
+// Write keeps the record, the header decides which columns are numeric: derived columns may hold text so they stay
+// strings
+func (w *parquetWriter) Write(record []string) error {
+	if w.header != nil {
+		w.records = append(w.records, record)
+		return nil
+	}
+	w.numeric = make([]bool, len(record))
+	for i, col := range record {
+		w.numeric[i] = stringList(numericColumns).contains(col)
+	}
+	if len(config.ColumnNames) > 0 {
+		record = renameColumns(record)
+	}
+	w.header = record
+	return nil
+}
+
+// Flush is a no-op, the file is written once the report is complete
+func (w *parquetWriter) Flush() {}
+
+// writeFile writes the parquet file, a data page per column followed by the file metadata
+func (w *parquetWriter) writeFile(out io.Writer) error {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+	chunks := make([][]byte, 0, len(w.header))
+	totalSize := int64(0)
+	if len(w.records) > 0 {
+		for i := range w.header {
+			offset := int64(file.Len())
+			page := w.columnPage(i)
+			file.Write(page)
+			totalSize += int64(len(page))
+			chunks = append(chunks, w.columnChunk(i, offset, int64(len(page))))
+		}
+	}
+
+	meta := newThriftWriter()
+	meta.i32(1, 1)
+	meta.listBegin(2, thriftStruct, len(w.header)+1)
+	meta.structBegin()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(w.header)))
+	meta.structEnd()
+	for i, name := range w.header {
+		meta.structBegin()
+		if w.numeric[i] {
+			meta.i32(1, parquetDouble)
+		} else {
+			meta.i32(1, parquetByteArray)
+		}
+		meta.i32(3, parquetOptional)
+		meta.binary(4, name)
+		if !w.numeric[i] {
+			meta.i32(6, parquetUTF8)
+		}
+		meta.structEnd()
+	}
+	meta.i64(3, int64(len(w.records)))
+	if len(chunks) == 0 {
+		meta.listBegin(4, thriftStruct, 0)
+	} else {
+		meta.listBegin(4, thriftStruct, 1)
+		meta.structBegin()
+		meta.listBegin(1, thriftStruct, len(chunks))
+		for _, chunk := range chunks {
+			meta.buf.Write(chunk)
+		}
+		meta.i64(2, totalSize)
+		meta.i64(3, int64(len(w.records)))
+		meta.structEnd()
+	}
+	meta.binary(6, "slos version "+version)
+	meta.structEnd()
+
+	file.Write(meta.buf.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(meta.buf.Len()))
+	file.WriteString(parquetMagic)
+	_, err := file.WriteTo(out)
+	return err
+}
+
+// columnPage returns the page header and data page of the column: the definition levels (1 for values, 0 for
+// nulls) then the plain encoded values
+func (w *parquetWriter) columnPage(i int) []byte {
+	levels := make([]byte, (len(w.records)+7)/8)
+	var values bytes.Buffer
+	for n, record := range w.records {
+		value := ""
+		if i < len(record) {
+			value = record[i]
+		}
+		if value == "" {
+			continue
+		}
+		if w.numeric[i] {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			binary.Write(&values, binary.LittleEndian, math.Float64bits(f))
+		} else {
+			binary.Write(&values, binary.LittleEndian, uint32(len(value)))
+			values.WriteString(value)
+		}
+		levels[n/8] |= 1 << uint(n%8)
+	}
+
+	// the levels are a single bit packed run of groups of 8, prefixed by their length
+	var run bytes.Buffer
+	writeUvarint(&run, uint64(len(levels))<<1|1)
+	run.Write(levels)
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, uint32(run.Len()))
+	run.WriteTo(&data)
+	values.WriteTo(&data)
+
+	header := newThriftWriter()
+	header.i32(1, parquetDataPage)
+	header.i32(2, int32(data.Len()))
+	header.i32(3, int32(data.Len()))
+	header.fieldBegin(5, thriftStruct)
+	header.structBegin()
+	header.i32(1, int32(len(w.records)))
+	header.i32(2, parquetPlain)
+	header.i32(3, parquetRLE)
+	header.i32(4, parquetRLE)
+	header.structEnd()
+	header.structEnd()
+	return append(header.buf.Bytes(), data.Bytes()...)
+}
+
+// columnChunk returns the thrift encoded column chunk of the column's page at offset, as a list element
+func (w *parquetWriter) columnChunk(i int, offset, size int64) []byte {
+	chunk := newThriftWriter()
+	chunk.i64(2, offset)
+	chunk.fieldBegin(3, thriftStruct)
+	chunk.structBegin()
+	if w.numeric[i] {
+		chunk.i32(1, parquetDouble)
+	} else {
+		chunk.i32(1, parquetByteArray)
+	}
+	chunk.listBegin(2, thriftI32, 2)
+	writeUvarint(&chunk.buf, zigzag(parquetPlain))
+	writeUvarint(&chunk.buf, zigzag(parquetRLE))
+	chunk.listBegin(3, thriftBinary, 1)
+	writeUvarint(&chunk.buf, uint64(len(w.header[i])))
+	chunk.buf.WriteString(w.header[i])
+	chunk.i32(4, 0)
+	chunk.i64(5, int64(len(w.records)))
+	chunk.i64(6, size)
+	chunk.i64(7, size)
+	chunk.i64(9, offset)
+	chunk.structEnd()
+	chunk.structEnd()
+	return chunk.buf.Bytes()
+}
+
+// thriftWriter encodes structs with the thrift compact protocol, the parquet metadata encoding
+type thriftWriter struct {
+	buf bytes.Buffer
+	// lastIDs are the last field ids of the structs being written, field ids are encoded as deltas
+	lastIDs []int16
+}
+
+// newThriftWriter returns a writer of a top level struct, ended by structEnd
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastIDs: []int16{0}}
+}
+
+// fieldBegin writes the header of the field of the current struct
+func (t *thriftWriter) fieldBegin(id int16, fieldType byte) {
+	last := t.lastIDs[len(t.lastIDs)-1]
+	t.lastIDs[len(t.lastIDs)-1] = id
+	if delta := id - last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+		return
+	}
+	t.buf.WriteByte(fieldType)
+	writeUvarint(&t.buf, zigzag(int64(id)))
+}
+
+// structBegin starts a struct, a list element or a field value after fieldBegin
+func (t *thriftWriter) structBegin() {
+	t.lastIDs = append(t.lastIDs, 0)
+}
+
+// structEnd writes the stop field of the current struct
+func (t *thriftWriter) structEnd() {
+	t.buf.WriteByte(0)
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+// i32 writes an i32 field, enums included
+func (t *thriftWriter) i32(id int16, value int32) {
+	t.fieldBegin(id, thriftI32)
+	writeUvarint(&t.buf, zigzag(int64(value)))
+}
+
+// i64 writes an i64 field
+func (t *thriftWriter) i64(id int16, value int64) {
+	t.fieldBegin(id, thriftI64)
+	writeUvarint(&t.buf, zigzag(value))
+}
+
+// binary writes a string field
+func (t *thriftWriter) binary(id int16, value string) {
+	t.fieldBegin(id, thriftBinary)
+	writeUvarint(&t.buf, uint64(len(value)))
+	t.buf.WriteString(value)
+}
+
+// listBegin writes the header of a list field of size elements of the element type, the elements follow
+func (t *thriftWriter) listBegin(id int16, elemType byte, size int) {
+	t.fieldBegin(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elemType)
+	writeUvarint(&t.buf, uint64(size))
+}
+
+// zigzag maps signed integers to unsigned so small negative numbers have short varints
+func zigzag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
+
+// writeUvarint writes the unsigned varint
+func writeUvarint(buf *bytes.Buffer, value uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], value)])
+}
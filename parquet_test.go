@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes thrift compact structs into maps of field id to value: int64, string, []interface{} or
+// map[int16]interface{}
+type thriftReader struct {
+	t    *testing.T
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("bad varint at %d", r.pos)
+	}
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) value(fieldType byte) interface{} {
+	switch fieldType {
+	case thriftI32, thriftI64:
+		u := r.uvarint()
+		return int64(u>>1) ^ -int64(u&1)
+	case thriftBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		return string(r.data[r.pos-n : r.pos])
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		fields := map[int16]interface{}{}
+		last := int16(0)
+		for {
+			header := r.data[r.pos]
+			r.pos++
+			if header == 0 {
+				return fields
+			}
+			id := last + int16(header>>4)
+			if header>>4 == 0 {
+				u := r.uvarint()
+				id = int16(int64(u>>1) ^ -int64(u&1))
+			}
+			fields[id] = r.value(header & 0x0f)
+			last = id
+		}
+	}
+	r.t.Fatalf("unexpected thrift type %d at %d", fieldType, r.pos)
+	return nil
+}
+
+// readParquet returns the file metadata and the values of each column, nil for nulls
+func readParquet(t *testing.T, file []byte) (map[int16]interface{}, [][]interface{}) {
+	t.Helper()
+	n := len(file)
+	if string(file[:4]) != parquetMagic || string(file[n-4:]) != parquetMagic {
+		t.Fatalf("missing magic")
+	}
+	size := int(binary.LittleEndian.Uint32(file[n-8 : n-4]))
+	footer := &thriftReader{t: t, data: file[n-8-size : n-8]}
+	meta := footer.value(thriftStruct).(map[int16]interface{})
+	if footer.pos != size {
+		t.Fatalf("footer decoded %d bytes of %d", footer.pos, size)
+	}
+
+	schema := meta[2].([]interface{})
+	var columns [][]interface{}
+	for _, group := range meta[4].([]interface{}) {
+		for i, chunk := range group.(map[int16]interface{})[1].([]interface{}) {
+			column := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			offset := int(column[9].(int64))
+			page := &thriftReader{t: t, data: file, pos: offset}
+			header := page.value(thriftStruct).(map[int16]interface{})
+			data := file[page.pos : page.pos+int(header[2].(int64))]
+			if page.pos+len(data)-offset != int(column[7].(int64)) {
+				t.Errorf("column %d: size %d, page is %d bytes", i, column[7], page.pos+len(data)-offset)
+			}
+			rows := int(header[5].(map[int16]interface{})[1].(int64))
+
+			levels := &thriftReader{t: t, data: data, pos: 4}
+			groups := levels.uvarint() >> 1
+			bits := data[levels.pos : levels.pos+int(groups)]
+			values := data[4+int(binary.LittleEndian.Uint32(data)):]
+			physical := schema[i+1].(map[int16]interface{})[1].(int64)
+			var got []interface{}
+			for row := 0; row < rows; row++ {
+				if bits[row/8]&(1<<uint(row%8)) == 0 {
+					got = append(got, nil)
+					continue
+				}
+				if physical == parquetDouble {
+					got = append(got, math.Float64frombits(binary.LittleEndian.Uint64(values)))
+					values = values[8:]
+					continue
+				}
+				n := int(binary.LittleEndian.Uint32(values))
+				got = append(got, string(values[4:4+n]))
+				values = values[4+n:]
+			}
+			if len(values) != 0 {
+				t.Errorf("column %d: %d bytes left", i, len(values))
+			}
+			columns = append(columns, got)
+		}
+	}
+	return meta, columns
+}
+
+func TestParquetWriter(t *testing.T) {
+	saved := config.ColumnNames
+	defer func() { config.ColumnNames = saved }()
+	config.ColumnNames = map[string]string{"overall_status": "SLI (%)"}
+
+	tests := []struct {
+		name    string
+		records [][]string
+		schema  []string
+		columns [][]interface{}
+	}{
+		{
+			name:    "header only",
+			records: [][]string{{"slo_name", "target"}},
+			schema:  []string{"schema", "slo_name", "target"},
+		},
+		{
+			name: "values and nulls",
+			records: [][]string{
+				{"slo_name", "overall_status", "note"},
+				{"checkout", "99.5", "x"},
+				{"search", "", ""},
+				{"login", "N/A", "héllo, \"world\""},
+			},
+			schema: []string{"schema", "slo_name", "SLI (%)", "note"},
+			columns: [][]interface{}{
+				{"checkout", "search", "login"},
+				{99.5, nil, nil},
+				{"x", nil, "héllo, \"world\""},
+			},
+		},
+		{
+			name: "more rows than a level group",
+			records: [][]string{
+				{"target"}, {"1"}, {"2"}, {""}, {"4"}, {"5"}, {"6"}, {"7"}, {"8"}, {"-9.25"},
+			},
+			schema:  []string{"schema", "target"},
+			columns: [][]interface{}{{1.0, 2.0, nil, 4.0, 5.0, 6.0, 7.0, 8.0, -9.25}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &parquetWriter{}
+			for _, record := range tt.records {
+				if err := writer.Write(record); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := writer.writeFile(&buf); err != nil {
+				t.Fatal(err)
+			}
+			meta, columns := readParquet(t, buf.Bytes())
+
+			if rows := meta[3].(int64); rows != int64(len(tt.records)-1) {
+				t.Errorf("num_rows = %d, want %d", rows, len(tt.records)-1)
+			}
+			var names []string
+			for _, element := range meta[2].([]interface{}) {
+				names = append(names, element.(map[int16]interface{})[4].(string))
+			}
+			if !reflect.DeepEqual(names, tt.schema) {
+				t.Errorf("schema = %q, want %q", names, tt.schema)
+			}
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %v, want %v", columns, tt.columns)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// splitDaily splits the row time span into rows for each utc calendar day, the first and last days may be partial
+func splitDaily(row reportRow) []reportRow {
+	var days []reportRow
+	for start := row.from.UTC(); start.Before(row.to); {
+		end := startOfDay(start).Add(OneDay)
+		if end.After(row.to) {
+			end = row.to
+		}
+		day := row
+		day.from, day.to, day.period = start, end, start.Format("2006-01-02")
+		days = append(days, day)
+		start = end
+	}
+	return days
+}
+
+// splitWeekly returns rows for the last n complete iso weeks (monday to monday utc) before now, oldest first
+func splitWeekly(row reportRow, n int, now time.Time) []reportRow {
+	today := startOfDay(now)
+	daysSinceMonday := (int(today.Weekday()) + 6) % 7
+	end := today.AddDate(0, 0, -daysSinceMonday)
+
+	var weeks []reportRow
+	for i := n; i > 0; i-- {
+		from := end.AddDate(0, 0, -7*i)
+		year, week := from.ISOWeek()
+		w := row
+		w.from, w.to = from, from.AddDate(0, 0, 7)
+		w.timeframe, w.period = "weekly", fmt.Sprintf("%d-W%02d", year, week)
+		weeks = append(weeks, w)
+	}
+	return weeks
+}
+
+// startOfDay returns midnight utc of the day t falls in
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// startOfMonth returns midnight utc of the first day of the month t falls in
+func startOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// window is a rolling window length in days
+type window int
+
+// duration returns the window length
+func (w window) duration() time.Duration {
+	return time.Duration(w) * OneDay
+}
+
+// String returns the window as a timeframe e.g 14d
+func (w window) String() string {
+	return fmt.Sprintf("%dd", int(w))
+}
+
+// Set parses a number of days e.g 14d
+func (w *window) Set(value string) error {
+	days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+	if err != nil || !strings.HasSuffix(value, "d") || days <= 0 {
+		return fmt.Errorf("invalid window: %s, expected a number of days e.g 14d", value)
+	}
+	*w = window(days)
+	return nil
+}
+
+// windowList is a flag.Value for comma separated windows e.g 14d,45d
+type windowList []window
+
+// String returns the comma separated windows
+func (l *windowList) String() string {
+	names := make([]string, 0, len(*l))
+	for _, w := range *l {
+		names = append(names, w.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Set parses comma separated windows e.g 14d,45d
+func (l *windowList) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		var w window
+		if err := w.Set(strings.TrimSpace(name)); err != nil {
+			return err
+		}
+		*l = append(*l, w)
+	}
+	return nil
+}
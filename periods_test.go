@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// span is a period and its utc time span
+type span struct {
+	period   string
+	from, to string
+}
+
+// rowSpans returns the periods and utc time spans of the rows
+func rowSpans(rows []reportRow) []span {
+	spans := make([]span, 0, len(rows))
+	for _, row := range rows {
+		spans = append(spans, span{row.period, row.from.UTC().Format(time.RFC3339), row.to.UTC().Format(time.RFC3339)})
+	}
+	return spans
+}
+
+func TestSplitDaily(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(value string) time.Time {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []span
+	}{
+		{"empty span", utc("2024-01-01T00:00:00Z"), utc("2024-01-01T00:00:00Z"), []span{}},
+		{"whole days", utc("2024-01-01T00:00:00Z"), utc("2024-01-03T00:00:00Z"), []span{
+			{"2024-01-01", "2024-01-01T00:00:00Z", "2024-01-02T00:00:00Z"},
+			{"2024-01-02", "2024-01-02T00:00:00Z", "2024-01-03T00:00:00Z"},
+		}},
+		{"month end with partial days", utc("2024-01-30T12:00:00Z"), utc("2024-02-01T06:30:00Z"), []span{
+			{"2024-01-30", "2024-01-30T12:00:00Z", "2024-01-31T00:00:00Z"},
+			{"2024-01-31", "2024-01-31T00:00:00Z", "2024-02-01T00:00:00Z"},
+			{"2024-02-01", "2024-02-01T00:00:00Z", "2024-02-01T06:30:00Z"},
+		}},
+		{"leap day", utc("2024-02-28T00:00:00Z"), utc("2024-03-01T00:00:00Z"), []span{
+			{"2024-02-28", "2024-02-28T00:00:00Z", "2024-02-29T00:00:00Z"},
+			{"2024-02-29", "2024-02-29T00:00:00Z", "2024-03-01T00:00:00Z"},
+		}},
+		{"year end", utc("2023-12-31T23:00:00Z"), utc("2024-01-01T01:00:00Z"), []span{
+			{"2023-12-31", "2023-12-31T23:00:00Z", "2024-01-01T00:00:00Z"},
+			{"2024-01-01", "2024-01-01T00:00:00Z", "2024-01-01T01:00:00Z"},
+		}},
+		{
+			// the local day losing an hour to dst is split on utc days
+			"dst start in local time",
+			time.Date(2024, time.March, 10, 0, 0, 0, 0, newYork), time.Date(2024, time.March, 11, 0, 0, 0, 0, newYork),
+			[]span{
+				{"2024-03-10", "2024-03-10T05:00:00Z", "2024-03-11T00:00:00Z"},
+				{"2024-03-11", "2024-03-11T00:00:00Z", "2024-03-11T04:00:00Z"},
+			},
+		},
+		{
+			"dst end in local time",
+			time.Date(2024, time.November, 3, 0, 0, 0, 0, newYork), time.Date(2024, time.November, 4, 0, 0, 0, 0, newYork),
+			[]span{
+				{"2024-11-03", "2024-11-03T04:00:00Z", "2024-11-04T00:00:00Z"},
+				{"2024-11-04", "2024-11-04T00:00:00Z", "2024-11-04T05:00:00Z"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rowSpans(splitDaily(reportRow{timeframe: "30d", from: tt.from, to: tt.to}))
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitDaily() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("splitDaily() day %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitWeekly(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		n    int
+		want []span
+	}{
+		{"monday midnight", time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), 2, []span{
+			{"2024-W01", "2024-01-01T00:00:00Z", "2024-01-08T00:00:00Z"},
+			{"2024-W02", "2024-01-08T00:00:00Z", "2024-01-15T00:00:00Z"},
+		}},
+		{"sunday night, the current week is incomplete", time.Date(2024, time.January, 14, 23, 59, 0, 0, time.UTC), 1, []span{
+			{"2024-W01", "2024-01-01T00:00:00Z", "2024-01-08T00:00:00Z"},
+		}},
+		{"iso week of the previous year", time.Date(2021, time.January, 6, 12, 0, 0, 0, time.UTC), 1, []span{
+			{"2020-W53", "2020-12-28T00:00:00Z", "2021-01-04T00:00:00Z"},
+		}},
+		{"local time is aligned on utc weeks", time.Date(2024, time.January, 15, 1, 0, 0, 0, time.FixedZone("CET", 3600)), 1, []span{
+			{"2024-W02", "2024-01-08T00:00:00Z", "2024-01-15T00:00:00Z"},
+		}},
+		{"no weeks", time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), 0, []span{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weeks := splitWeekly(reportRow{timeframe: "7d"}, tt.n, tt.now)
+			got := rowSpans(weeks)
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitWeekly() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("splitWeekly() week %d = %v, want %v", i, got[i], tt.want[i])
+				}
+				if weeks[i].timeframe != "weekly" {
+					t.Errorf("splitWeekly() week %d timeframe = %s, want weekly", i, weeks[i].timeframe)
+				}
+			}
+		})
+	}
+}
+
+func TestReportSpans(t *testing.T) {
+	saved := options.sleep
+	defer func() { options.sleep = saved }()
+	options.sleep = time.Second
+	weeks := splitWeekly(reportRow{}, 4, time.Date(2024, time.January, 29, 0, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name     string
+		stopAt   int
+		reported int
+	}{
+		{"all spans", -1, 4},
+		{"stopped during the first pause", 0, 1},
+		{"stopped during the third pause", 2, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeClock(t, time.Date(2024, time.January, 29, 0, 0, 0, 0, time.UTC))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			reported := 0
+			reportSpans(ctx, weeks, func(reportRow) {
+				if reported == tt.stopAt {
+					cancel()
+				}
+				reported++
+			})
+			if reported != tt.reported {
+				t.Errorf("reportSpans() reported %d spans, want %d", reported, tt.reported)
+			}
+		})
+	}
+}
+
+func TestWindowListSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"14d", "14d", false},
+		{"14d,45d", "14d,45d", false},
+		{"14d, 45d", "14d,45d", false},
+		{"1d", "1d", false},
+		{"14", "", true},
+		{"0d", "", true},
+		{"-7d", "", true},
+		{"2w", "", true},
+		{"d", "", true},
+		{"1.5d", "", true},
+		{"14d,", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var windows windowList
+			err := windows.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set(%q) = %v, want error %t", tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := windows.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowDuration(t *testing.T) {
+	tests := []struct {
+		window window
+		want   time.Duration
+	}{
+		{window(1), 24 * time.Hour},
+		{window(14), 14 * 24 * time.Hour},
+		{window(45), 45 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window.String(), func(t *testing.T) {
+			if got := tt.window.duration(); got != tt.want {
+				t.Errorf("duration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
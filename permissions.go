@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+
+	"slos/schema"
+)
+
+// permission results of check-permissions
+const (
+	permissionOK      = "OK"
+	permissionMissing = "MISSING"
+	permissionUnknown = "UNKNOWN"
+)
+
+// permissionCheck is a feature of the tool and the application key scope it needs, read features are probed with an
+// api call, write features are only checked against the key scopes
+type permissionCheck struct {
+	feature string
+	uses    string
+	scope   string
+	probe   func(ctx context.Context) error
+}
+
+// permissionChecks are the features check-permissions reports on
+var permissionChecks = []permissionCheck{
+	{"read SLOs", "report, list, snapshot, backup and most subcommands", "slos_read", probeSLOs},
+	{"write SLOs", "tag, restore, clone, delete", "slos_write", nil},
+	{"read monitors", "audit-alerts, provision-alerts, -downtimes, -mute-status", "monitors_read", func(ctx context.Context) error {
+		return datadogGet(ctx, "/api/v1/monitor", url.Values{"page": {"0"}, "page_size": {"1"}}, nil)
+	}},
+	{"write monitors", "provision-alerts", "monitors_write", nil},
+	{"read teams", "-resolve-teams, -require-team", "teams_read", func(ctx context.Context) error {
+		return datadogGet(ctx, "/api/v2/team", url.Values{"page[size]": {"1"}}, nil)
+	}},
+	{"read incidents", "-incidents", "incident_read", func(ctx context.Context) error {
+		return datadogGet(ctx, "/api/v2/incidents", url.Values{"page[size]": {"1"}}, nil)
+	}},
+	{"read audit trail", "SLO change history", "audit_logs_read", func(ctx context.Context) error {
+		return datadogGet(ctx, "/api/v2/audit/events", url.Values{"page[limit]": {"1"}}, nil)
+	}},
+	{"write dashboards", "dashboard", "dashboards_write", nil},
+	{"write notebooks", "-notebook", "notebooks_write", nil},
+}
+
+// probeSLOs lists a single slo
+func probeSLOs(ctx context.Context) error {
+	limit, offset := int64(1), int64(0)
+	_, httpResp, err := newAPIClient().ServiceLevelObjectivesApi.ListSLOs(ctx,
+		datadog.ListSLOsOptionalParameters{Limit: &limit, Offset: &offset})
+	return classifyAPIError(err, httpResp)
+}
+
+// appKeyScopes returns the scopes of DD_APP_KEY, found among the current user's application keys by its last 4
+// characters, unscoped keys have the permissions of their user's roles
+func appKeyScopes(ctx context.Context) (scopes []string, scoped bool, err error) {
+	var resp struct {
+		Data []struct {
+			Attributes struct {
+				Last4  string    `json:"last4"`
+				Scopes *[]string `json:"scopes"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := datadogGet(ctx, "/api/v2/current_user/application_keys", url.Values{"page[size]": {"100"}}, &resp); err != nil {
+		return nil, false, err
+	}
+	appKey := os.Getenv("DD_APP_KEY")
+	for _, key := range resp.Data {
+		if len(appKey) >= 4 && key.Attributes.Last4 == appKey[len(appKey)-4:] {
+			if key.Attributes.Scopes == nil {
+				return nil, false, nil
+			}
+			return *key.Attributes.Scopes, true, nil
+		}
+	}
+	return nil, false, errors.New("DD_APP_KEY is not one of the current user's application keys e.g a service account key")
+}
+
+// checkPermission returns the result of the feature's check and its detail
+func checkPermission(ctx context.Context, check permissionCheck, scopes []string, scoped bool) (string, string) {
+	if scoped && !stringList(scopes).contains(check.scope) {
+		return permissionMissing, "the application key is not scoped for " + check.scope
+	}
+	if check.probe == nil {
+		if scoped {
+			return permissionOK, "the application key is scoped for " + check.scope
+		}
+		return permissionUnknown, "not probed, depends on the " + check.scope + " permission of the key user's roles"
+	}
+	if err := check.probe(ctx); err != nil {
+		if errorType(err) == schema.ErrorUnauthorized {
+			return permissionMissing, err.Error()
+		}
+		return permissionUnknown, err.Error()
+	}
+	return permissionOK, ""
+}
+
+// runCheckPermissions writes which of the tool's features the application key can use to stdout as csv, and exits
+// with status 1 when it can't read slos
+func runCheckPermissions(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("check-permissions", flag.ContinueOnError)
+	parseFlags(fs, args)
+
+	scopes, scoped, err := appKeyScopes(ctx)
+	if err != nil {
+		log.Printf("Unable to get the application key scopes, features are probed only, err: %s", err)
+	} else if scoped {
+		log.Printf("Application key scopes: %s", strings.Join(scopes, ", "))
+	} else {
+		log.Printf("Application key is not scoped, it has the permissions of its user's roles")
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	if err := writer.Write([]string{"feature", "used_by", "scope", "result", "detail"}); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+	canReadSLOs := true
+	for _, check := range permissionChecks {
+		result, detail := checkPermission(ctx, check, scopes, scoped)
+		if check.scope == "slos_read" && result != permissionOK {
+			canReadSLOs = false
+		}
+		if err := writer.Write([]string{check.feature, check.uses, check.scope, result, detail}); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+	}
+	writer.Flush()
+	if !canReadSLOs {
+		fatalf("The application key can't read SLOs, reports will fail")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// errorPolicy is a flag.Value deciding when api errors stop a run, continue (the default), fail-fast or max-errors=N
+type errorPolicy struct {
+	// maxErrors stops the run once reached, 0 never stops
+	maxErrors int
+}
+
+// String returns the policy e.g max-errors=10
+func (p *errorPolicy) String() string {
+	switch p.maxErrors {
+	case 0:
+		return "continue"
+	case 1:
+		return "fail-fast"
+	}
+	return fmt.Sprintf("max-errors=%d", p.maxErrors)
+}
+
+// Set parses continue, fail-fast or max-errors=N
+func (p *errorPolicy) Set(value string) error {
+	switch {
+	case value == "continue":
+		p.maxErrors = 0
+		return nil
+	case value == "fail-fast":
+		p.maxErrors = 1
+		return nil
+	case strings.HasPrefix(value, "max-errors="):
+		n, err := strconv.Atoi(strings.TrimPrefix(value, "max-errors="))
+		if err == nil && n > 0 {
+			p.maxErrors = n
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid error policy: %s, expected continue, fail-fast or max-errors=N", value)
+}
+
+// apiErrors counts the failed api calls of the run, updated atomically as timeframes are fetched concurrently
+var apiErrors int64
+
+// recordAPIError counts a failed api call
+func recordAPIError() {
+	atomic.AddInt64(&apiErrors, 1)
+}
+
+// stop returns true when the run should stop because of the api errors so far
+func (p *errorPolicy) stop() bool {
+	return p.maxErrors > 0 && atomic.LoadInt64(&apiErrors) >= int64(p.maxErrors)
+}
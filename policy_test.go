@@ -0,0 +1,71 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestErrorPolicySet(t *testing.T) {
+	tests := []struct {
+		value     string
+		maxErrors int
+		want      string
+		wantErr   bool
+	}{
+		{"continue", 0, "continue", false},
+		{"fail-fast", 1, "fail-fast", false},
+		{"max-errors=10", 10, "max-errors=10", false},
+		{"max-errors=1", 1, "fail-fast", false},
+		{"max-errors=0", 0, "", true},
+		{"max-errors=-1", 0, "", true},
+		{"max-errors=", 0, "", true},
+		{"max-errors=ten", 0, "", true},
+		{"max-errors", 0, "", true},
+		{"Continue", 0, "", true},
+		{"", 0, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var p errorPolicy
+			err := p.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set(%q) = %v, want error %t", tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if p.maxErrors != tt.maxErrors {
+				t.Errorf("Set(%q) max errors = %d, want %d", tt.value, p.maxErrors, tt.maxErrors)
+			}
+			if got := p.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorPolicyStop(t *testing.T) {
+	saved := atomic.LoadInt64(&apiErrors)
+	defer atomic.StoreInt64(&apiErrors, saved)
+	tests := []struct {
+		name      string
+		maxErrors int
+		errors    int64
+		stop      bool
+	}{
+		{"continue never stops", 0, 100, false},
+		{"fail-fast without errors", 1, 0, false},
+		{"fail-fast after an error", 1, 1, true},
+		{"below max errors", 10, 9, false},
+		{"max errors reached", 10, 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt64(&apiErrors, tt.errors)
+			p := errorPolicy{maxErrors: tt.maxErrors}
+			if got := p.stop(); got != tt.stop {
+				t.Errorf("stop() = %t, want %t", got, tt.stop)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// offlineSubcommands don't call the datadog api, so they run without the preflight check
+var offlineSubcommands = stringList{"convert", "grafana-dashboard", "login", "logout", "merge"}
+
+// preflight checks the datadog keys are set, the api key is valid and the keys can read slos,
+// so a run fails before it starts instead of writing a report full of error rows
+func preflight(ctx context.Context) error {
+	for _, name := range []string{"DD_API_KEY", "DD_APP_KEY"} {
+		if os.Getenv(name) == "" {
+			return fmt.Errorf("%s is not set", name)
+		}
+	}
+
+	apiClient := newAPIClient()
+	if _, httpResp, err := apiClient.AuthenticationApi.Validate(ctx); err != nil {
+		if httpResp != nil && httpResp.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("DD_API_KEY is invalid for site %s", datadogSite())
+		}
+		return fmt.Errorf("unable to validate DD_API_KEY: %s", err)
+	}
+
+	limit, offset := int64(1), int64(0)
+	_, httpResp, err := apiClient.ServiceLevelObjectivesApi.ListSLOs(ctx, datadog.ListSLOsOptionalParameters{Limit: &limit, Offset: &offset})
+	if err != nil {
+		if httpResp != nil && (httpResp.StatusCode == http.StatusForbidden || httpResp.StatusCode == http.StatusUnauthorized) {
+			return fmt.Errorf("DD_APP_KEY is invalid or lacks the slos_read scope")
+		}
+		return fmt.Errorf("unable to list slos: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// previousColumns compare a row to the same length window immediately before it, with -previous-period
+var previousColumns = []string{"previous_sli", "sli_change", "budget_consumed_change", "regressed"}
+
+// previousPeriod is the sli and error budget consumed of the window before a row's window
+type previousPeriod struct {
+	sliValue            *float64
+	errorBudgetConsumed *float64
+}
+
+// loadPreviousPeriod returns the sli and error budget consumed of the same length window ending where the row's
+// window starts, nil when the history call fails
+func loadPreviousPeriod(ctx context.Context, apiClient *datadog.APIClient, row reportRow) *previousPeriod {
+	prev := row
+	prev.from, prev.to = row.from.Add(-row.to.Sub(row.from)), row.from
+	history, err := getChunkedSLOHistory(ctx, apiClient, prev.slo, prev.threshold, prev.from, prev.to)
+	summary.recordHistoryCall(err)
+	if err != nil {
+		log.Printf("Unable to get previous period slo history s: %s, tf: %s, err: %s", row.slo.GetId(), row.timeframeLabel(), err)
+		return nil
+	}
+	prev, err = newHistoryRow(prev, *history.Data.Overall)
+	if err != nil {
+		return nil
+	}
+	if series, ok := history.Data.GetSeriesOk(); ok && series.Denominator.Sum == 0 {
+		return &previousPeriod{}
+	}
+	return &previousPeriod{sliValue: prev.sliValue, errorBudgetConsumed: prev.errorBudgetConsumed}
+}
+
+// previousValues returns the previous window's sli, the sli and error budget consumed changes since and whether
+// the row regressed, consuming more than -regression-threshold points of error budget more, group rows are left empty
+func (r reportRow) previousValues() []string {
+	if r.previous == nil || r.group != "" || r.previous.sliValue == nil || r.sliValue == nil {
+		values := make([]string, len(previousColumns))
+		if r.previous != nil && r.group == "" {
+			values[0] = formatOptionalFloat(r.previous.sliValue)
+		}
+		return values
+	}
+	sliChange := *r.sliValue - *r.previous.sliValue
+	budgetChange := *r.errorBudgetConsumed - *r.previous.errorBudgetConsumed
+	return []string{
+		formatOptionalFloat(r.previous.sliValue),
+		formatOptionalFloat(&sliChange),
+		formatOptionalFloat(&budgetChange),
+		fmt.Sprintf("%t", budgetChange > options.regressionThreshold),
+	}
+}
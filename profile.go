@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// cpuProfile is the -cpuprofile file while profiling
+var cpuProfile *os.File
+
+// startProfiling serves the net/http/pprof endpoints on the -pprof address and starts the -cpuprofile, if set
+func startProfiling() error {
+	if options.pprofAddr != "" {
+		go func() {
+			if err := http.ListenAndServe(options.pprofAddr, nil); err != nil {
+				log.Printf("Unable to serve pprof: %s, err: %s", options.pprofAddr, err)
+			}
+		}()
+		log.Printf("Serving pprof at: http://%s/debug/pprof/", options.pprofAddr)
+	}
+	if options.cpuProfile == "" {
+		return nil
+	}
+	f, err := os.Create(options.cpuProfile)
+	if err != nil {
+		return err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	cpuProfile = f
+	return nil
+}
+
+// stopProfiling stops the cpu profile and writes the -memprofile heap profile, if set
+func stopProfiling() {
+	if cpuProfile != nil {
+		pprof.StopCPUProfile()
+		cpuProfile.Close()
+		cpuProfile = nil
+		log.Printf("CPU profile written to: %s", options.cpuProfile)
+	}
+	if options.memProfile == "" {
+		return
+	}
+	f, err := os.Create(options.memProfile)
+	if err != nil {
+		log.Printf("Unable to write heap profile: %s, err: %s", options.memProfile, err)
+		return
+	}
+	defer f.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		log.Printf("Unable to write heap profile: %s, err: %s", options.memProfile, err)
+		return
+	}
+	log.Printf("Heap profile written to: %s", options.memProfile)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// progressState is the run progress dumped on SIGUSR1
+type progressState struct {
+	ProcessedSLOs  int64          `json:"processed_slos"`
+	ListedSLOs     int64          `json:"listed_slos"`
+	RemainingSLOs  int64          `json:"remaining_slos"`
+	HistoryCalls   int            `json:"history_calls"`
+	Failed         int            `json:"failed"`
+	FailedByType   map[string]int `json:"failed_by_type"`
+	ElapsedSeconds float64        `json:"elapsed_seconds"`
+	CallsPerSecond float64        `json:"calls_per_second"`
+}
+
+// processedSLOs and listedSLOs (by the slo stream) count the slos of the run, updated atomically
+var processedSLOs, listedSLOs int64
+
+// dumpProgress logs the run progress, and writes it as json to -status-file if set
+func dumpProgress() {
+	s := summary.snapshot()
+	state := progressState{
+		ProcessedSLOs:  atomic.LoadInt64(&processedSLOs),
+		ListedSLOs:     atomic.LoadInt64(&listedSLOs),
+		HistoryCalls:   s.HistoryCalls,
+		Failed:         s.Failed,
+		FailedByType:   s.FailedByType,
+		ElapsedSeconds: since(startedAt).Seconds(),
+	}
+	state.RemainingSLOs = state.ListedSLOs - state.ProcessedSLOs
+	if state.ElapsedSeconds > 0 {
+		state.CallsPerSecond = float64(state.HistoryCalls) / state.ElapsedSeconds
+	}
+	log.Printf("Progress: %d of %d SLOs listed so far processed (%d remaining), %d history calls (%d failed) in %s (%.2f calls/s)",
+		state.ProcessedSLOs, state.ListedSLOs, state.RemainingSLOs, state.HistoryCalls, state.Failed,
+		time.Duration(state.ElapsedSeconds*float64(time.Second)).Round(time.Second), state.CallsPerSecond)
+
+	if options.statusFile == "" {
+		return
+	}
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(options.statusFile, content, 0644)
+	}
+	if err != nil {
+		log.Printf("Unable to write status: %s, err: %s", options.statusFile, err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchProgressSignal dumps the run progress on each SIGUSR1 e.g kill -USR1 <pid>
+func watchProgressSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	go func() {
+		for range signals {
+			dumpProgress()
+		}
+	}()
+}
@@ -0,0 +1,4 @@
+package main
+
+// watchProgressSignal does nothing on windows which has no SIGUSR1
+func watchProgressSignal() {}
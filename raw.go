@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// rawResponseColumn holds the raw history response json, or the sidecar file path, when enabled
+const rawResponseColumn = "raw_response"
+
+// rawResponseEnabled returns true when raw history responses are included in the report
+func rawResponseEnabled() bool {
+	return options.rawJSON || options.rawDir != ""
+}
+
+// rawHistory returns the raw json of the history response, or the path of the sidecar file it was written to
+func rawHistory(row reportRow, history datadog.SLOHistoryResponse) (string, error) {
+	content, err := json.Marshal(history)
+	if err != nil {
+		return "", err
+	}
+	return saveRaw(row, content)
+}
+
+// rawError returns the raw response body of a failed api call, if any, the api error is unwrapped from its error type
+func rawError(row reportRow, err error) (string, error) {
+	var apiErr datadog.GenericOpenAPIError
+	if !errors.As(err, &apiErr) || len(apiErr.Body()) == 0 {
+		return "", nil
+	}
+	return saveRaw(row, apiErr.Body())
+}
+
+// saveRaw writes content (encrypted if enabled) to a sidecar file named after the row when -raw-dir is set returning
+// its path, otherwise the content is returned for the column
+func saveRaw(row reportRow, content []byte) (string, error) {
+	if options.rawDir == "" {
+		return string(content), nil
+	}
+	name := fmt.Sprintf("%s_%s_%d_%d.json", row.slo.GetId(), row.timeframeLabel(), row.from.UTC().Unix(), row.to.UTC().Unix())
+	path, err := writeReportFile(filepath.Join(options.rawDir, name), content)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,9 @@
+package main
+
+// requireWritable exits when the run is read-only (the default), before a subcommand or option modifies datadog,
+// so reporting credentials and jobs never change slos, monitors or dashboards by accident
+func requireWritable(what string) {
+	if options.readOnly {
+		fatalf("Refusing to run %s, it modifies datadog and the run is read-only, run with -read-only=false to allow changes", what)
+	}
+}
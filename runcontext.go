@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// newRunContext returns the context of the report run, done after -run-timeout, on the first SIGINT or SIGTERM, or
+// when the run stalls for -stall-timeout, listing, history calls and sleeps stop once it is done, a second signal
+// kills the run
+func newRunContext() (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if options.runTimeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), options.runTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Printf("Received %s, stopping the run, signal again to kill it", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	if options.stallTimeout > 0 {
+		watchStalls(ctx, cancel, options.stallTimeout)
+	}
+	return datadog.NewDefaultContext(ctx), cancel
+}
@@ -0,0 +1,220 @@
+// Package schema defines the slo report columns and rows, so programs reading reports look columns up by name
+// instead of by position, which changes as optional columns are enabled or added between versions
+package schema
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the version of the report columns, bumped whenever columns are added, removed or reordered
+const Version = 2
+
+// Columns are the columns every report starts with, in order, optional and derived columns follow them
+var Columns = []string{
+	"name",
+	"slo_id",
+	"timeframe",
+	"group",
+	"period",
+	"from (utc)",
+	"to (utc)",
+	"from_ts",
+	"to_ts",
+	"target",
+	"warning",
+	"overall_status",
+	"error_budget_consumed",
+	"status",
+	"error (only if applicable)",
+	"error_type",
+}
+
+// status classifications of the sli against the warning and target thresholds
+const (
+	StatusOK       = "OK"
+	StatusWarning  = "WARNING"
+	StatusBreached = "BREACHED"
+	// StatusDeleted is the status of slos deleted between listing and fetching their history
+	StatusDeleted = "DELETED"
+	// StatusNoData is the status of windows without data e.g no events, unless -no-data counts them as pass or fail
+	StatusNoData = "NO_DATA"
+)
+
+// error types of error rows, so automation can handle categories of errors differently e.g retry RATE_LIMITED rows
+const (
+	// ErrorRateLimited is an api call rejected by the rate limit (http 429)
+	ErrorRateLimited = "RATE_LIMITED"
+	// ErrorNotFound is an slo or resource that does not exist (http 404) e.g deleted since it was listed
+	ErrorNotFound = "NOT_FOUND"
+	// ErrorUnauthorized is an api call the keys are not valid or lack the scope for (http 401 / 403)
+	ErrorUnauthorized = "UNAUTHORIZED"
+	// ErrorTimeout is an api call that timed out, in the client or the api (http 408 / 504)
+	ErrorTimeout = "TIMEOUT"
+	// ErrorNoData is a history response without the data the row is computed from
+	ErrorNoData = "NO_DATA"
+	// ErrorUnsupported is a timeframe or slo the report does not support
+	ErrorUnsupported = "UNSUPPORTED"
+	// ErrorNetwork is an api call that failed to connect
+	ErrorNetwork = "NETWORK"
+	// ErrorAPI is any other api error e.g a server error or an error in the response body
+	ErrorAPI = "API_ERROR"
+	// ErrorOther is an error that is not an api error e.g an slo without a known team
+	ErrorOther = "OTHER"
+)
+
+// ReportRow is a report row, for an slo timeframe or one of its groups or periods, the csv tags are the column
+// names, from and to are written both as utc times and unix timestamps
+type ReportRow struct {
+	Name      string    `json:"name" csv:"name"`
+	SLOID     string    `json:"slo_id" csv:"slo_id"`
+	Timeframe string    `json:"timeframe" csv:"timeframe"`
+	Group     string    `json:"group,omitempty" csv:"group"`
+	Period    string    `json:"period,omitempty" csv:"period"`
+	From      time.Time `json:"from" csv:"from_ts"`
+	To        time.Time `json:"to" csv:"to_ts"`
+	Target    float64   `json:"target" csv:"target"`
+	Warning   *float64  `json:"warning,omitempty" csv:"warning"`
+	// SLI is unset when the window has no data or the history call failed
+	SLI                 *float64 `json:"sli,omitempty" csv:"overall_status"`
+	ErrorBudgetConsumed *float64 `json:"error_budget_consumed,omitempty" csv:"error_budget_consumed"`
+	Status              string   `json:"status" csv:"status"`
+	Error               string   `json:"error,omitempty" csv:"error"`
+	ErrorType           string   `json:"error_type,omitempty" csv:"error_type"`
+	// Extra are the optional and derived columns of the report by name e.g tag_team or budget_consumed_mean
+	Extra map[string]string `json:"extra,omitempty" csv:"-"`
+}
+
+// Record returns the row's values of Columns, in order
+func (r ReportRow) Record() []string {
+	return []string{
+		r.Name,
+		r.SLOID,
+		r.Timeframe,
+		r.Group,
+		r.Period,
+		fmt.Sprintf("%s", r.From.UTC()),
+		fmt.Sprintf("%s", r.To.UTC()),
+		fmt.Sprintf("%d", r.From.UTC().Unix()),
+		fmt.Sprintf("%d", r.To.UTC().Unix()),
+		fmt.Sprintf("%f", r.Target),
+		formatFloat(r.Warning),
+		formatFloat(r.SLI),
+		formatFloat(r.ErrorBudgetConsumed),
+		r.Status,
+		r.Error,
+		r.ErrorType,
+	}
+}
+
+// formatFloat returns the number with 6 decimals, empty when unset
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return fmt.Sprintf("%f", *f)
+}
+
+// ParseRecord returns the row of a report record, columns are looked up by name in the header so any column order
+// and optional columns are read, columns other than Columns are kept in Extra. Reports written with renamed
+// columns (column_names) or locale formatting (-decimal-separator, -date-format, -na) are not parsed
+func ParseRecord(header, record []string) (ReportRow, error) {
+	values := map[string]string{}
+	row := ReportRow{Extra: map[string]string{}}
+	for i, col := range header {
+		if i >= len(record) {
+			break
+		}
+		if isColumn(col) {
+			values[strings.SplitN(col, " (", 2)[0]] = record[i]
+		} else {
+			row.Extra[col] = record[i]
+		}
+	}
+	for _, col := range []string{"slo_id", "from_ts", "to_ts"} {
+		if _, found := values[col]; !found {
+			return ReportRow{}, fmt.Errorf("missing %s column", col)
+		}
+	}
+
+	row.Name, row.SLOID, row.Timeframe = values["name"], values["slo_id"], values["timeframe"]
+	row.Group, row.Period = values["group"], values["period"]
+	row.Status, row.Error, row.ErrorType = values["status"], values["error"], values["error_type"]
+	var err error
+	if row.From, err = parseTimestamp(values["from_ts"]); err != nil {
+		return ReportRow{}, fmt.Errorf("slo %s: invalid from_ts: %s", row.SLOID, values["from_ts"])
+	}
+	if row.To, err = parseTimestamp(values["to_ts"]); err != nil {
+		return ReportRow{}, fmt.Errorf("slo %s: invalid to_ts: %s", row.SLOID, values["to_ts"])
+	}
+	if value := values["target"]; value != "" {
+		if row.Target, err = strconv.ParseFloat(value, 64); err != nil {
+			return ReportRow{}, fmt.Errorf("slo %s: invalid target: %s", row.SLOID, value)
+		}
+	}
+	for col, f := range map[string]**float64{"warning": &row.Warning, "overall_status": &row.SLI, "error_budget_consumed": &row.ErrorBudgetConsumed} {
+		if *f, err = parseOptionalFloat(values[col]); err != nil {
+			return ReportRow{}, fmt.Errorf("slo %s: invalid %s: %s", row.SLOID, col, values[col])
+		}
+	}
+	return row, nil
+}
+
+// ReadCSV returns the rows of a csv report, written with or without a utf-8 byte order mark
+func ReadCSV(r io.Reader) ([]ReportRow, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := append([]string{}, records[0]...)
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\xef\xbb\xbf")
+	}
+	rows := make([]ReportRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row, err := ParseRecord(header, record)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// isColumn returns true when col is one of Columns
+func isColumn(col string) bool {
+	for _, name := range Columns {
+		if col == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimestamp parses a unix timestamp in seconds
+func parseTimestamp(value string) (time.Time, error) {
+	ts, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts, 0).UTC(), nil
+}
+
+// parseOptionalFloat parses a number, returning nil for an empty value
+func parseOptionalFloat(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRecord(t *testing.T) {
+	sli, consumed, warning := 99.5, 50.0, 99.95
+	row := ReportRow{
+		Name:                "checkout latency",
+		SLOID:               "abc123",
+		Timeframe:           "30d",
+		From:                time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+		To:                  time.Date(2023, 5, 31, 0, 0, 0, 0, time.UTC),
+		Target:              99,
+		Warning:             &warning,
+		SLI:                 &sli,
+		ErrorBudgetConsumed: &consumed,
+		Status:              StatusOK,
+		Extra:               map[string]string{},
+	}
+	failed := row
+	failed.Warning, failed.SLI, failed.ErrorBudgetConsumed = nil, nil, nil
+	failed.Status, failed.Error, failed.ErrorType = "", "api error: timeout", ErrorTimeout
+	withTag := row
+	withTag.Extra = map[string]string{"tag_team": "checkout"}
+
+	// the columns in a different order, missing the optional group and period
+	reordered := []string{"slo_id", "name", "timeframe", "from_ts", "to_ts", "target", "overall_status", "status"}
+
+	tests := []struct {
+		name   string
+		header []string
+		record []string
+		want   ReportRow
+		err    bool
+	}{
+		{"row", Columns, row.Record(), row, false},
+		{"error row", Columns, failed.Record(), failed, false},
+		{"extra column", append(append([]string{}, Columns...), "tag_team"), append(row.Record(), "checkout"), withTag, false},
+		{"reordered columns", reordered, []string{"abc123", "checkout latency", "30d", "1682899200", "1685491200", "99.000000", "99.500000", StatusOK},
+			ReportRow{Name: "checkout latency", SLOID: "abc123", Timeframe: "30d", From: row.From, To: row.To, Target: 99, SLI: &sli, Status: StatusOK, Extra: map[string]string{}}, false},
+		{"missing slo_id", Columns[2:], row.Record()[2:], ReportRow{}, true},
+		{"invalid from_ts", []string{"slo_id", "from_ts", "to_ts"}, []string{"abc123", "yesterday", "1685491200"}, ReportRow{}, true},
+		{"invalid sli", []string{"slo_id", "from_ts", "to_ts", "overall_status"}, []string{"abc123", "1682899200", "1685491200", "99,5"}, ReportRow{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRecord(tt.header, tt.record)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %t", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRecord() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// scorecardConfig are the scorecard grading criteria, a service scores 100 less the weight of each criterion
+// times the fraction of its slos failing it, graded A (90+), B (80+), C (70+), D (60+) or F
+type scorecardConfig struct {
+	// BreachWeight is for slos breaching their first target
+	BreachWeight float64 `json:"breach_weight"`
+	// BurnRateWeight is for slos burning error budget faster than sustainable over the last 7 days
+	BurnRateWeight float64 `json:"burn_rate_weight"`
+	// AlertWeight is for slos without burn rate or error budget alerts
+	AlertWeight float64 `json:"alert_weight"`
+	// StaleWeight is for slos not modified in StaleDays
+	StaleWeight float64 `json:"stale_weight"`
+	StaleDays   int     `json:"stale_days"`
+}
+
+// defaultScorecard are the grading criteria used unless the config has a scorecard
+var defaultScorecard = scorecardConfig{BreachWeight: 40, BurnRateWeight: 30, AlertWeight: 20, StaleWeight: 10, StaleDays: 365}
+
+// scorecardSLO is an slo graded in a service scorecard
+type scorecardSLO struct {
+	ID       string   `json:"slo_id"`
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	SLI      *float64 `json:"sli"`
+	BurnRate *float64 `json:"burn_rate_7d"`
+	Alerted  bool     `json:"alerted"`
+	Stale    bool     `json:"stale"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// scorecard grades the slos of a service
+type scorecard struct {
+	Service   string         `json:"service"`
+	Grade     string         `json:"grade"`
+	Score     float64        `json:"score"`
+	Breached  int            `json:"breached"`
+	Burning   int            `json:"burning"`
+	Unalerted int            `json:"unalerted"`
+	Stale     int            `json:"stale"`
+	SLOs      []scorecardSLO `json:"slos"`
+}
+
+// runScorecard grades each service (the slo service: tag) A to F on breaches, burn rate, alert coverage and
+// slo freshness, writing a scorecard json per service and an org rollup csv to the directory
+func runScorecard(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("scorecard", flag.ContinueOnError)
+	dir := fs.String("dir", "scorecards", "directory the service scorecards and org rollup are written to")
+	parseFlags(fs, args)
+
+	criteria := defaultScorecard
+	if config.Scorecard != nil {
+		criteria = *config.Scorecard
+	}
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	apiClient := newAPIClient()
+	monitors, err := listAllMonitors(ctx, apiClient, "")
+	if err != nil {
+		fatalf("Error when calling `MonitorsApi.ListMonitors`: %v\n", err)
+	}
+	burnRateAlerts, errorBudgetAlerts := countSLOAlerts(monitors)
+
+	now := runClock.Now().UTC()
+	services := map[string]*scorecard{}
+	org := &scorecard{Service: "(org)"}
+	for counter, slo := range slos {
+		log.Printf("(%d of %d) Grading s: %s", counter+1, len(slos), slo.GetId())
+		graded := gradeSLO(ctx, apiClient, withTargetOverride(slo), now, criteria)
+		graded.Alerted = burnRateAlerts[slo.GetId()]+errorBudgetAlerts[slo.GetId()] > 0
+		service := sloTagValue(slo, "service")
+		if service == "" {
+			service = "(none)"
+		}
+		if services[service] == nil {
+			services[service] = &scorecard{Service: service}
+		}
+		services[service].add(graded)
+		org.add(graded)
+		if err := sleepContext(ctx, options.sleep); err != nil {
+			fatalf("Scorecard stopped after %d of %d SLOs, no scorecard written, err: %s", counter+1, len(slos), err)
+		}
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fatalf("Unable to create directory: %s, err: %s", *dir, err)
+	}
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rollup := make([]*scorecard, 0, len(names)+1)
+	for _, name := range names {
+		card := services[name]
+		card.grade(criteria)
+		path := filepath.Join(*dir, scorecardFileName(name)+".json")
+		content, err := json.MarshalIndent(card, "", "  ")
+		if err == nil {
+			err = ioutil.WriteFile(path, content, 0644)
+		}
+		if err != nil {
+			fatalf("Unable to write to file: %s, err: %s", path, err)
+		}
+		rollup = append(rollup, card)
+	}
+	org.grade(criteria)
+	rollup = append(rollup, org)
+	if err := writeScorecardRollup(filepath.Join(*dir, "org.csv"), rollup); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", filepath.Join(*dir, "org.csv"), err)
+	}
+	log.Printf("Scorecards of %d services written to: %s, org grade: %s", len(names), *dir, org.Grade)
+}
+
+// gradeSLO returns the slo's status against its first target and 7 day burn rate, the burn rate is the
+// error budget consumed over the last 7 days as a multiple of a 7 day budget (above 1 is unsustainable)
+func gradeSLO(ctx context.Context, apiClient *datadog.APIClient, slo datadog.ServiceLevelObjective, now time.Time, criteria scorecardConfig) scorecardSLO {
+	graded := scorecardSLO{
+		ID:    slo.GetId(),
+		Name:  slo.GetName(),
+		Stale: criteria.StaleDays > 0 && now.Sub(time.Unix(slo.GetModifiedAt(), 0)) > time.Duration(criteria.StaleDays)*OneDay,
+	}
+	if len(slo.Thresholds) == 0 {
+		graded.Error = "slo has no thresholds"
+		return graded
+	}
+	threshold := slo.Thresholds[0]
+	from, to, err := getSLOTimeSpanFromTimeframe(threshold.Timeframe, now)
+	if err != nil {
+		graded.Error = err.Error()
+		return graded
+	}
+
+	row := reportRow{slo: slo, threshold: threshold, from: from, to: to}
+	row, err = scorecardHistory(ctx, apiClient, row)
+	if err != nil {
+		graded.Error = err.Error()
+		return graded
+	}
+	graded.Status, graded.SLI = row.status(), row.sliValue
+
+	week := reportRow{slo: slo, threshold: threshold, from: now.Add(-SevenDays), to: now}
+	week, err = scorecardHistory(ctx, apiClient, week)
+	if err != nil {
+		graded.Error = err.Error()
+		return graded
+	}
+	if week.errorBudgetConsumed != nil {
+		burnRate := *week.errorBudgetConsumed / 100
+		graded.BurnRate = &burnRate
+	}
+	return graded
+}
+
+// scorecardHistory returns the row with the overall history of its window
+func scorecardHistory(ctx context.Context, apiClient *datadog.APIClient, row reportRow) (reportRow, error) {
+	history, err := getChunkedSLOHistory(ctx, apiClient, row.slo, row.threshold, row.from, row.to)
+	summary.recordHistoryCall(err)
+	if err != nil {
+		return row, err
+	}
+	return newHistoryRow(row, *history.Data.Overall)
+}
+
+// add adds a graded slo to the scorecard
+func (c *scorecard) add(slo scorecardSLO) {
+	c.SLOs = append(c.SLOs, slo)
+	if slo.Status == StatusBreached {
+		c.Breached++
+	}
+	if slo.BurnRate != nil && *slo.BurnRate > 1 {
+		c.Burning++
+	}
+	if !slo.Alerted {
+		c.Unalerted++
+	}
+	if slo.Stale {
+		c.Stale++
+	}
+}
+
+// grade scores and grades the scorecard from the fraction of slos failing each criterion
+func (c *scorecard) grade(criteria scorecardConfig) {
+	if len(c.SLOs) == 0 {
+		c.Score, c.Grade = 100, "A"
+		return
+	}
+	n := float64(len(c.SLOs))
+	c.Score = 100 -
+		criteria.BreachWeight*float64(c.Breached)/n -
+		criteria.BurnRateWeight*float64(c.Burning)/n -
+		criteria.AlertWeight*float64(c.Unalerted)/n -
+		criteria.StaleWeight*float64(c.Stale)/n
+	switch {
+	case c.Score >= 90:
+		c.Grade = "A"
+	case c.Score >= 80:
+		c.Grade = "B"
+	case c.Score >= 70:
+		c.Grade = "C"
+	case c.Score >= 60:
+		c.Grade = "D"
+	default:
+		c.Grade = "F"
+	}
+}
+
+// scorecardFileName returns the service as a file name, characters other than letters, digits, - and _ replaced
+func scorecardFileName(service string) string {
+	return regexp.MustCompile(`[^A-Za-z0-9_-]`).ReplaceAllString(service, "_")
+}
+
+// writeScorecardRollup writes a row per scorecard to the csv file
+func writeScorecardRollup(path string, cards []*scorecard) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer, err := newCSVWriter(file)
+	if err != nil {
+		return err
+	}
+	if err := writer.Write([]string{"service", "grade", "score", "slos", "breached", "burning", "unalerted", "stale"}); err != nil {
+		return err
+	}
+	for _, card := range cards {
+		data := []string{
+			card.Service,
+			card.Grade,
+			fmt.Sprintf("%.1f", card.Score),
+			fmt.Sprintf("%d", len(card.SLOs)),
+			fmt.Sprintf("%d", card.Breached),
+			fmt.Sprintf("%d", card.Burning),
+			fmt.Sprintf("%d", card.Unalerted),
+			fmt.Sprintf("%d", card.Stale),
+		}
+		if err := writer.Write(data); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// searchPageSize is the number of slos requested per search page
+const searchPageSize = 100
+
+// sloIDBatchSize is the number of slo ids requested per ListSLOs call, long ids params are rejected by the api
+const sloIDBatchSize = 100
+
+// searchSLOResponse is the part of the /api/v1/slo/search response used, the api client version in use predates it
+type searchSLOResponse struct {
+	Data struct {
+		Attributes struct {
+			SLOs []struct {
+				Data struct {
+					ID string `json:"id"`
+				} `json:"data"`
+			} `json:"slos"`
+		} `json:"attributes"`
+	} `json:"data"`
+	Meta struct {
+		Pagination struct {
+			NextNumber *int64 `json:"next_number"`
+			LastNumber int64  `json:"last_number"`
+			Total      int64  `json:"total"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+// searchSLOs returns the slos matching the full text search query (name, description and facets e.g team:ninja),
+// following the search pages and loading the full slo definitions by id
+func searchSLOs(ctx context.Context, query string) ([]datadog.ServiceLevelObjective, error) {
+	var allSLOs []datadog.ServiceLevelObjective
+	err := searchSLOPages(ctx, query, func(page []datadog.ServiceLevelObjective) error {
+		allSLOs = append(allSLOs, page...)
+		return nil
+	})
+	if err != nil {
+		return []datadog.ServiceLevelObjective{}, err
+	}
+	log.Printf("Loaded %d SLOs \n", len(allSLOs))
+	return allSLOs, nil
+}
+
+// searchSLOPages calls fn with each page of slos matching the full text search query, the ids are searched first
+// then the full slo definitions loaded in batches of ids
+func searchSLOPages(ctx context.Context, query string, fn sloPageFunc) error {
+	log.Printf("Searching SLOs for query %s", query)
+	var ids []string
+	for page := int64(0); ; {
+		params := url.Values{}
+		params.Set("query", query)
+		params.Set("page[size]", fmt.Sprintf("%d", searchPageSize))
+		params.Set("page[number]", fmt.Sprintf("%d", page))
+		var resp searchSLOResponse
+		if err := datadogSend(ctx, http.MethodGet, "/api/v1/slo/search", params, nil, &resp); err != nil {
+			return err
+		}
+		for _, slo := range resp.Data.Attributes.SLOs {
+			ids = append(ids, slo.Data.ID)
+		}
+		pagination := resp.Meta.Pagination
+		log.Printf("Found %d SLOs, total SLOs %d \n", len(ids), pagination.Total)
+		if len(resp.Data.Attributes.SLOs) == 0 || pagination.NextNumber == nil ||
+			*pagination.NextNumber <= page || page >= pagination.LastNumber {
+			break
+		}
+		page = *pagination.NextNumber
+		if err := sleepContext(ctx, 1*time.Second); err != nil {
+			return err
+		}
+	}
+	return getSLOPagesByID(ctx, ids, fn)
+}
+
+// getSLOsByID returns the slo definitions of the ids, ids of deleted slos are left out
+func getSLOsByID(ctx context.Context, ids []string) ([]datadog.ServiceLevelObjective, error) {
+	var slos []datadog.ServiceLevelObjective
+	err := getSLOPagesByID(ctx, ids, func(page []datadog.ServiceLevelObjective) error {
+		slos = append(slos, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slos, nil
+}
+
+// getSLOPagesByID calls fn with the slo definitions of each batch of ids, ids of deleted slos are left out
+func getSLOPagesByID(ctx context.Context, ids []string, fn sloPageFunc) error {
+	apiClient := newAPIClient()
+	for _, batch := range batchIDs(ids, sloIDBatchSize) {
+		chunk := strings.Join(batch, ",")
+		resp, _, err := apiClient.ServiceLevelObjectivesApi.ListSLOs(ctx, *datadog.NewListSLOsOptionalParameters().WithIds(chunk))
+		if err != nil {
+			return err
+		}
+		if err := fn(resp.GetData()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchIDs splits the ids in batches of at most size ids
+func batchIDs(ids []string, size int) [][]string {
+	var batches [][]string
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batches = append(batches, ids[start:end])
+	}
+	return batches
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestBatchIDs(t *testing.T) {
+	ids := func(n int) []string {
+		var ids []string
+		for i := 0; i < n; i++ {
+			ids = append(ids, fmt.Sprintf("slo%d", i))
+		}
+		return ids
+	}
+	tests := []struct {
+		name  string
+		ids   []string
+		sizes []int
+	}{
+		{"no ids", nil, nil},
+		{"single batch", ids(3), []int{3}},
+		{"exact batches", ids(200), []int{100, 100}},
+		{"partial last batch", ids(1001), []int{100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := batchIDs(tt.ids, sloIDBatchSize)
+			var sizes []int
+			var joined []string
+			for _, batch := range batches {
+				sizes = append(sizes, len(batch))
+				joined = append(joined, batch...)
+			}
+			if !reflect.DeepEqual(sizes, tt.sizes) {
+				t.Errorf("batchIDs() sizes = %v, want %v", sizes, tt.sizes)
+			}
+			if !reflect.DeepEqual(joined, tt.ids) {
+				t.Errorf("batchIDs() ids = %v, want %v", joined, tt.ids)
+			}
+		})
+	}
+}
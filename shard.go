@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// shard is a flag.Value selecting one of count partitions of the slos by slo id e.g 2/5, index is 1 based
+type shard struct {
+	index, count int
+}
+
+// String returns the shard e.g 2/5, empty when not set
+func (s *shard) String() string {
+	if s.count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", s.index, s.count)
+}
+
+// Set parses a shard e.g 2/5
+func (s *shard) Set(value string) error {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) == 2 {
+		index, indexErr := strconv.Atoi(parts[0])
+		count, countErr := strconv.Atoi(parts[1])
+		if indexErr == nil && countErr == nil && count > 0 && index >= 1 && index <= count {
+			s.index, s.count = index, count
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid shard: %s, expected INDEX/COUNT e.g 2/5", value)
+}
+
+// contains returns true when the slo id hashes into the shard, every id is in exactly one shard of the count
+func (s *shard) contains(sloID string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(sloID))
+	return int(h.Sum32()%uint32(s.count)) == s.index-1
+}
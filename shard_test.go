@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShardSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"2/5", "2/5", false},
+		{"1/1", "1/1", false},
+		{"5/5", "5/5", false},
+		{"0/5", "", true},
+		{"6/5", "", true},
+		{"1/0", "", true},
+		{"2", "", true},
+		{"a/5", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var s shard
+			err := s.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set(%q) = %v, want error %t", tt.value, err, tt.wantErr)
+			}
+			if got := s.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShardContains(t *testing.T) {
+	ids := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, fmt.Sprintf("%032x", i*7919))
+	}
+	tests := []struct {
+		name  string
+		count int
+	}{
+		{"single shard", 1},
+		{"two shards", 2},
+		{"five shards", 5},
+		{"more shards than slos", 2000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shards := make([]*shard, 0, tt.count)
+			for index := 1; index <= tt.count; index++ {
+				shards = append(shards, &shard{index: index, count: tt.count})
+			}
+			for _, id := range ids {
+				matched := 0
+				for _, s := range shards {
+					if s.contains(id) {
+						matched++
+						// the assignment only depends on the id
+						if !(&shard{index: s.index, count: s.count}).contains(id) {
+							t.Errorf("%s in shard %s is not stable", id, s)
+						}
+					}
+				}
+				if matched != 1 {
+					t.Errorf("%s is in %d of %d shards, want exactly 1", id, matched, tt.count)
+				}
+			}
+		})
+	}
+}
+
+func TestShardContainsKnownIDs(t *testing.T) {
+	// changing the hash moves slos between the shards of scheduled runs
+	tests := []struct {
+		id    string
+		shard int
+	}{
+		{"abc123", 4},
+		{"0c1b2a3d4e5f60718293a4b5c6d7e8f9", 2},
+		{"", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			s := &shard{index: tt.shard, count: 5}
+			if !s.contains(tt.id) {
+				t.Errorf("%q is not in shard %s", tt.id, s)
+			}
+		})
+	}
+}
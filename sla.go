@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// slaColumns compare the sli to the contractual sla target, with -sla-targets
+var slaColumns = []string{"sla_target", "sla_status", "sla_risk"}
+
+// slaTargets are contractual sla targets per slo id and per service (the slo service: tag), slo ids first
+type slaTargets struct {
+	SLOs     map[string]float64 `json:"slos"`
+	Services map[string]float64 `json:"services"`
+}
+
+// contractTargets are the sla targets loaded from -sla-targets
+var contractTargets *slaTargets
+
+// loadSLATargets loads the sla targets from a json file e.g {"slos": {"slo_id": 99.5}, "services": {"checkout": 99.9}}
+func loadSLATargets(path string) (*slaTargets, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	targets := &slaTargets{}
+	if err := json.Unmarshal(content, targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// target returns the sla target of the slo, by id then by service
+func (t *slaTargets) target(slo datadog.ServiceLevelObjective) (float64, bool) {
+	if target, found := t.SLOs[slo.GetId()]; found {
+		return target, true
+	}
+	target, found := t.Services[sloTagValue(slo, "service")]
+	return target, found
+}
+
+// slaValues returns the sla target, MET or BREACHED and whether the sla is at risk: breached while the
+// internal slo is not, empty when the slo has no sla target or the row no sli
+func (r reportRow) slaValues() []string {
+	target, found := contractTargets.target(r.slo)
+	if !found {
+		return []string{"", "", ""}
+	}
+	if r.sliValue == nil {
+		return []string{formatOptionalFloat(&target), "", ""}
+	}
+	status, risk := "MET", "false"
+	if *r.sliValue < target {
+		status = StatusBreached
+		if r.status() != StatusBreached {
+			risk = "true"
+		}
+	}
+	return []string{formatOptionalFloat(&target), status, risk}
+}
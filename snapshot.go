@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// sloSnapshot is the file written by the snapshot subcommand
+type sloSnapshot struct {
+	TakenAt time.Time                       `json:"taken_at"`
+	SLOs    []datadog.ServiceLevelObjective `json:"slos"`
+}
+
+// runSnapshot stores the definitions of the slos matching the tag query in a json file
+func runSnapshot(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	output := fs.String("o", "slo_snapshot.json", "path the snapshot json is written to")
+	parseFlags(fs, args)
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	content, err := json.MarshalIndent(sloSnapshot{TakenAt: runClock.Now().UTC(), SLOs: slos}, "", "  ")
+	if err != nil {
+		fatalf("Unable to encode snapshot, err: %s", err)
+	}
+	if err := ioutil.WriteFile(*output, content, 0644); err != nil {
+		fatalf("Unable to write to file: %s, err: %s", *output, err)
+	}
+	log.Printf("Snapshot of %d SLOs written to: %s", len(slos), *output)
+}
+
+// runDrift compares the slos in a snapshot and the slos matching the tag query, writing each changed target,
+// query, tags or name and each added or deleted slo to stdout as csv
+func runDrift(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("drift", flag.ContinueOnError)
+	snapshotPath := fs.String("snapshot", "slo_snapshot.json", "path of the snapshot json written by the snapshot subcommand")
+	parseFlags(fs, args)
+
+	content, err := ioutil.ReadFile(*snapshotPath)
+	if err != nil {
+		fatalf("Unable to read snapshot: %s, err: %s", *snapshotPath, err)
+	}
+	var snapshot sloSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		fatalf("Unable to parse snapshot: %s, err: %s", *snapshotPath, err)
+	}
+
+	slos, err := getAllSLOs(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	current := map[string]datadog.ServiceLevelObjective{}
+	for _, slo := range slos {
+		current[slo.GetId()] = slo
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	cols := []string{"name", "slo_id", "change", "field", "before", "after"}
+	if err := writer.Write(cols); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+	write := func(slo datadog.ServiceLevelObjective, change, field, before, after string) {
+		if err := writer.Write([]string{slo.GetName(), slo.GetId(), change, field, before, after}); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+	}
+
+	// slos no longer matching the tag query (e.g their tags changed) are loaded by id, the ones not found were deleted
+	var missing []string
+	for _, before := range snapshot.SLOs {
+		if _, found := current[before.GetId()]; !found {
+			missing = append(missing, before.GetId())
+		}
+	}
+	unmatched, err := getSLOsByID(ctx, missing)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+	if config.TagNormalization != nil {
+		normalizeSLOTags(unmatched, config.TagNormalization)
+	}
+	remaining := map[string]bool{}
+	for id := range current {
+		remaining[id] = true
+	}
+	for _, slo := range unmatched {
+		current[slo.GetId()] = slo
+	}
+
+	changes := 0
+	for _, before := range snapshot.SLOs {
+		after, found := current[before.GetId()]
+		delete(remaining, before.GetId())
+		if !found {
+			write(before, "deleted", "", "", "")
+			changes++
+			continue
+		}
+		for _, field := range sloDefinitionFields {
+			if b, a := field.value(before), field.value(after); b != a {
+				write(after, "changed", field.name, b, a)
+				changes++
+			}
+		}
+	}
+
+	added := make([]datadog.ServiceLevelObjective, 0, len(remaining))
+	for id := range remaining {
+		added = append(added, current[id])
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].GetId() < added[j].GetId() })
+	for _, slo := range added {
+		write(slo, "added", "", "", "")
+		changes++
+	}
+	log.Printf("Done - %d changes since the snapshot taken at %s", changes, snapshot.TakenAt)
+}
+
+// sloDefinitionFields are the slo definition fields compared for drift and by restore, each of the user editable
+// fields sloDefinition sends
+var sloDefinitionFields = []struct {
+	name  string
+	value func(slo datadog.ServiceLevelObjective) string
+}{
+	{"name", func(slo datadog.ServiceLevelObjective) string { return slo.GetName() }},
+	{"description", func(slo datadog.ServiceLevelObjective) string { return slo.GetDescription() }},
+	{"type", func(slo datadog.ServiceLevelObjective) string { return string(slo.GetType()) }},
+	{"targets", func(slo datadog.ServiceLevelObjective) string {
+		targets := make([]string, 0, len(slo.Thresholds))
+		for _, threshold := range slo.Thresholds {
+			targets = append(targets, fmt.Sprintf("%s:%s/%s",
+				threshold.Timeframe, formatOptionalFloat(&threshold.Target), formatOptionalFloat(threshold.Warning)))
+		}
+		return strings.Join(targets, " ")
+	}},
+	{"query", func(slo datadog.ServiceLevelObjective) string {
+		query, ok := slo.GetQueryOk()
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("%s / %s", query.Numerator, query.Denominator)
+	}},
+	{"monitor_ids", func(slo datadog.ServiceLevelObjective) string {
+		ids := make([]string, 0, len(slo.GetMonitorIds()))
+		for _, id := range slo.GetMonitorIds() {
+			ids = append(ids, fmt.Sprintf("%d", id))
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}},
+	{"groups", func(slo datadog.ServiceLevelObjective) string {
+		groups := append([]string{}, slo.GetGroups()...)
+		sort.Strings(groups)
+		return strings.Join(groups, ",")
+	}},
+	{"tags", func(slo datadog.ServiceLevelObjective) string {
+		tags := append([]string{}, slo.GetTags()...)
+		sort.Strings(tags)
+		return strings.Join(tags, ",")
+	}},
+}
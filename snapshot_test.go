@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+func TestSLODefinitionFields(t *testing.T) {
+	base := func() datadog.ServiceLevelObjective {
+		slo := *datadog.NewServiceLevelObjective("checkout latency", []datadog.SLOThreshold{{Timeframe: "30d", Target: 99.9}}, datadog.SLOTYPE_MONITOR)
+		slo.SetDescription("p99 under 300ms")
+		slo.SetMonitorIds([]int64{2, 1})
+		slo.SetGroups([]string{"env:prod", "env:staging"})
+		slo.SetTags([]string{"team:checkout"})
+		return slo
+	}
+	tests := []struct {
+		name   string
+		change func(slo *datadog.ServiceLevelObjective)
+		want   []string
+	}{
+		{"unchanged", func(slo *datadog.ServiceLevelObjective) {}, nil},
+		{"reordered", func(slo *datadog.ServiceLevelObjective) {
+			slo.SetMonitorIds([]int64{1, 2})
+			slo.SetGroups([]string{"env:staging", "env:prod"})
+		}, nil},
+		{"description", func(slo *datadog.ServiceLevelObjective) { slo.SetDescription("p99 under 500ms") }, []string{"description"}},
+		{"type", func(slo *datadog.ServiceLevelObjective) { slo.SetType(datadog.SLOTYPE_METRIC) }, []string{"type"}},
+		{"groups", func(slo *datadog.ServiceLevelObjective) { slo.SetGroups([]string{"env:prod"}) }, []string{"groups"}},
+		{"target", func(slo *datadog.ServiceLevelObjective) { slo.Thresholds[0].Target = 99.5 }, []string{"targets"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := base(), base()
+			tt.change(&after)
+			var changed []string
+			for _, field := range sloDefinitionFields {
+				if field.value(before) != field.value(after) {
+					changed = append(changed, field.name)
+				}
+			}
+			if !reflect.DeepEqual(changed, tt.want) {
+				t.Errorf("changed fields = %v, want %v", changed, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// sloSource lists the slos a report is generated for, page by page
+type sloSource interface {
+	listPages(ctx context.Context, limit int64, fn sloPageFunc) error
+}
+
+// newSLOSource returns the -slo-source file or directory of slo definitions, or the api when unset
+func newSLOSource() (sloSource, error) {
+	if options.sloSource == "" {
+		return apiSource{query: options.query, tagQuery: options.tagQuery}, nil
+	}
+	if options.query != "" {
+		return nil, fmt.Errorf("-query searches the api, use -tagQuery with -slo-source")
+	}
+	info, err := os.Stat(options.sloSource)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() || strings.HasSuffix(options.sloSource, ".tar.gz") {
+		return fileSource{path: options.sloSource, tagQuery: options.tagQuery, read: readBackup}, nil
+	}
+	return fileSource{path: options.sloSource, tagQuery: options.tagQuery, read: readSLOFile}, nil
+}
+
+// apiSource lists the slos matching the tag query, or the full text search query when set, from the api
+type apiSource struct {
+	query, tagQuery string
+}
+
+// listPages calls fn with each page of slos listed or searched
+func (s apiSource) listPages(ctx context.Context, limit int64, fn sloPageFunc) error {
+	if s.query != "" {
+		return searchSLOPages(ctx, s.query, fn)
+	}
+	return listOrgSLOPages(ctx, limit, s.tagQuery, fn)
+}
+
+// fileSource lists the slos of saved definitions having the tag query tag, read with read
+type fileSource struct {
+	path, tagQuery string
+	read           func(path string) ([]datadog.ServiceLevelObjective, error)
+}
+
+// listPages calls fn with the slos having the tag query tag, limit per page
+func (s fileSource) listPages(ctx context.Context, limit int64, fn sloPageFunc) error {
+	slos, err := s.read(s.path)
+	if err != nil {
+		return fmt.Errorf("%s: %s", s.path, err)
+	}
+	var page []datadog.ServiceLevelObjective
+	for _, slo := range slos {
+		if s.tagQuery != "" && !stringList(slo.GetTags()).contains(s.tagQuery) {
+			continue
+		}
+		page = append(page, slo)
+		if int64(len(page)) == limit {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(page); err != nil {
+				return err
+			}
+			page = nil
+		}
+	}
+	if len(page) > 0 {
+		return fn(page)
+	}
+	return nil
+}
+
+// readSLOFile reads the slo definitions of a json file: a snapshot, a list slos api response or an array of slos
+func readSLOFile(path string) ([]datadog.ServiceLevelObjective, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(content)), "[") {
+		var slos []datadog.ServiceLevelObjective
+		err := json.Unmarshal(content, &slos)
+		return slos, err
+	}
+	var file struct {
+		SLOs []datadog.ServiceLevelObjective `json:"slos"`
+		Data []datadog.ServiceLevelObjective `json:"data"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+	return append(file.SLOs, file.Data...), nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// sloPageFunc is called with each page of slos listed, listing stops at the first error returned
+type sloPageFunc func(page []datadog.ServiceLevelObjective) error
+
+// errListingStopped stops listing once no more slos are needed
+var errListingStopped = errors.New("listing stopped")
+
+// sloFilter selects the slos in the -shard, a -sample random fraction of them, capped at -max-slos
+type sloFilter struct {
+	total    int
+	selected int
+}
+
+// keep returns true when the slo is selected, and false for more once -max-slos are selected
+func (f *sloFilter) keep(slo datadog.ServiceLevelObjective) (keep bool, more bool) {
+	f.total++
+	if options.maxSLOs > 0 && f.selected >= options.maxSLOs {
+		return false, false
+	}
+	if options.shard.count > 0 && !options.shard.contains(slo.GetId()) {
+		return false, true
+	}
+	if options.sample > 0 && rand.Float64() >= options.sample {
+		return false, true
+	}
+	f.selected++
+	return true, options.maxSLOs == 0 || f.selected < options.maxSLOs
+}
+
+// sloStream lists the slos of a source page by page in the background
+// so history is fetched while listing and only a page of slos is held at a time
+type sloStream struct {
+	slos chan datadog.ServiceLevelObjective
+	done chan struct{}
+	// err is the listing error, set before slos is closed
+	err error
+}
+
+// streamSLOs starts listing the slos of the source with recovered thresholds and normalized tags, without the
+// -ignore-file slos, filtered by -shard, -sample and -max-slos
+func streamSLOs(ctx context.Context, source sloSource, limit int64) *sloStream {
+	s := &sloStream{
+		slos: make(chan datadog.ServiceLevelObjective, limit),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(s.slos)
+		filter := &sloFilter{}
+		send := func(page []datadog.ServiceLevelObjective) error {
+			select {
+			case <-s.done:
+				return errListingStopped
+			default:
+			}
+			recoverThresholds(page)
+			if config.TagNormalization != nil {
+				normalizeSLOTags(page, config.TagNormalization)
+			}
+			for _, slo := range page {
+				if reason, ignored := ignoreReason(slo); ignored {
+					summary.recordIgnored(slo, reason)
+					continue
+				}
+				keep, more := filter.keep(slo)
+				if keep {
+					atomic.AddInt64(&listedSLOs, 1)
+					select {
+					case s.slos <- slo:
+					case <-s.done:
+						return errListingStopped
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+				if !more {
+					return errListingStopped
+				}
+			}
+			return nil
+		}
+
+		if err := source.listPages(ctx, limit, send); err != nil && err != errListingStopped {
+			s.err = err
+		}
+		if filter.selected < filter.total {
+			log.Printf("Processing %d of %d SLOs", filter.selected, filter.total)
+		}
+	}()
+	return s
+}
+
+// count returns the number of slos listed so far
+func (s *sloStream) count() int {
+	return int(atomic.LoadInt64(&listedSLOs))
+}
+
+// stop stops listing and returns the listing error, if any
+func (s *sloStream) stop() error {
+	close(s.done)
+	for range s.slos {
+	}
+	return s.err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// runSummary are the statistics of a report run
+type runSummary struct {
+	// mu guards the summary, progress dumps read it while the run updates it
+	mu sync.Mutex
+
+	SLOsListed   int            `json:"slos_listed"`
+	HistoryCalls int            `json:"history_calls"`
+	Succeeded    int            `json:"succeeded"`
+	Failed       int            `json:"failed"`
+	FailedByType map[string]int `json:"failed_by_type"`
+	// history calls are not retried, failed calls are written as error rows, Retries are of failed slo list pages
+	Retries         int     `json:"retries"`
+	Rows            int     `json:"rows"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	CallsPerSecond  float64 `json:"calls_per_second"`
+	StoppedByPolicy bool    `json:"stopped_by_error_policy"`
+	// CoalescedCalls are history calls answered with the response of the same call made for another row
+	CoalescedCalls int `json:"coalesced_calls"`
+	// Ignored are the slos left out by the -ignore-file
+	Ignored []ignoredSLO `json:"ignored"`
+	// ListDrift is set when slos changed while they were listed
+	ListDrift *listDrift `json:"list_drift,omitempty"`
+}
+
+// summary collects the statistics of the run
+var summary = runSummary{FailedByType: map[string]int{}, Ignored: []ignoredSLO{}}
+
+// recordIgnored adds an slo left out by the -ignore-file
+func (s *runSummary) recordIgnored(slo datadog.ServiceLevelObjective, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Ignored = append(s.Ignored, ignoredSLO{ID: slo.GetId(), Name: slo.GetName(), Reason: reason})
+}
+
+// recordRetry counts a retried slo list page
+func (s *runSummary) recordRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Retries++
+}
+
+// recordListDrift records that slos changed while they were listed
+func (s *runSummary) recordListDrift(drift listDrift) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ListDrift = &drift
+}
+
+// recordHistoryCall counts a history call, failures by error type
+func (s *runSummary) recordHistoryCall(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HistoryCalls++
+	if err == nil {
+		s.Succeeded++
+		return
+	}
+	s.Failed++
+	s.FailedByType[errorType(err)]++
+}
+
+// snapshot returns a copy of the summary so far
+func (s *runSummary) snapshot() runSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failedByType := make(map[string]int, len(s.FailedByType))
+	for errType, n := range s.FailedByType {
+		failedByType[errType] = n
+	}
+	return runSummary{
+		SLOsListed:   s.SLOsListed,
+		HistoryCalls: s.HistoryCalls,
+		Succeeded:    s.Succeeded,
+		Failed:       s.Failed,
+		FailedByType: failedByType,
+		Retries:      s.Retries,
+	}
+}
+
+// finish completes the summary at the end of the run, logs it and writes it as json if path is set
+func (s *runSummary) finish(rows int, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Rows = rows
+	s.DurationSeconds = since(startedAt).Seconds()
+	if s.DurationSeconds > 0 {
+		s.CallsPerSecond = float64(s.HistoryCalls) / s.DurationSeconds
+	}
+	s.StoppedByPolicy = options.errorPolicy.stop()
+	s.CoalescedCalls = historyCalls.coalescedCalls()
+
+	log.Printf("Summary: %d SLOs listed, %d history calls (%d succeeded, %d failed, %d retries), %d rows in %s (%.2f calls/s)",
+		s.SLOsListed, s.HistoryCalls, s.Succeeded, s.Failed, s.Retries, s.Rows,
+		time.Duration(s.DurationSeconds*float64(time.Second)).Round(time.Second), s.CallsPerSecond)
+	types := make([]string, 0, len(s.FailedByType))
+	for errType := range s.FailedByType {
+		types = append(types, errType)
+	}
+	sort.Strings(types)
+	for _, errType := range types {
+		log.Printf("Summary: %d failed with %s", s.FailedByType[errType], errType)
+	}
+	if s.CoalescedCalls > 0 {
+		log.Printf("Summary: %d duplicate history calls coalesced", s.CoalescedCalls)
+	}
+	if len(s.Ignored) > 0 {
+		log.Printf("Summary: %d SLOs ignored", len(s.Ignored))
+		for _, slo := range s.Ignored {
+			log.Printf("Summary: ignored s: %s (%s), reason: %s", slo.ID, slo.Name, slo.Reason)
+		}
+	}
+
+	if path == "" {
+		return
+	}
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(path, content, 0644)
+	}
+	if err != nil {
+		log.Printf("Unable to write summary: %s, err: %s", path, err)
+	}
+}
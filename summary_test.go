@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSummarySnapshot(t *testing.T) {
+	timeout := errors.New("timeout")
+	tests := []struct {
+		name                                     string
+		record                                   func(s *runSummary)
+		historyCalls, succeeded, failed, retries int
+		failedByType                             map[string]int
+	}{
+		{"empty", func(s *runSummary) {}, 0, 0, 0, 0, map[string]int{}},
+		{"history calls", func(s *runSummary) {
+			s.recordHistoryCall(nil)
+			s.recordHistoryCall(timeout)
+		}, 2, 1, 1, 0, map[string]int{errorType(timeout): 1}},
+		{"retries", func(s *runSummary) {
+			s.recordRetry()
+			s.recordRetry()
+		}, 0, 0, 0, 2, map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &runSummary{FailedByType: map[string]int{}}
+			tt.record(s)
+			got := s.snapshot()
+			if got.HistoryCalls != tt.historyCalls || got.Succeeded != tt.succeeded || got.Failed != tt.failed || got.Retries != tt.retries {
+				t.Errorf("snapshot() calls, succeeded, failed, retries = %d, %d, %d, %d, want %d, %d, %d, %d",
+					got.HistoryCalls, got.Succeeded, got.Failed, got.Retries, tt.historyCalls, tt.succeeded, tt.failed, tt.retries)
+			}
+			if !reflect.DeepEqual(got.FailedByType, tt.failedByType) {
+				t.Errorf("snapshot().FailedByType = %v, want %v", got.FailedByType, tt.failedByType)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// tableColumns are the report columns shown in terminal tables
+var tableColumns = []string{
+	"name",
+	"timeframe",
+	"group",
+	"period",
+	"target",
+	"overall_status",
+	"error_budget_consumed",
+	"status",
+	"error (only if applicable)",
+}
+
+// ansi colors used for the status column
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// minColumnWidth is the width columns are not truncated below when fitting the terminal width
+const minColumnWidth = 8
+
+// tableWriter buffers report records and renders them as an aligned table,
+// the records are only written when Render is called
+type tableWriter struct {
+	out     io.Writer
+	color   bool
+	indexes []int
+	rows    [][]string
+}
+
+// newTableWriter returns a table writer, colored unless NO_COLOR is set or out is not a terminal interpreting ansi colors
+func newTableWriter(out *os.File) *tableWriter {
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return &tableWriter{out: out, color: !noColor && isTerminal(out) && enableANSI(out)}
+}
+
+// Write buffers the table columns of the record, the first record is the header
+func (t *tableWriter) Write(record []string) error {
+	if t.indexes == nil {
+		for _, col := range tableColumns {
+			for i, name := range record {
+				if name == col {
+					t.indexes = append(t.indexes, i)
+				}
+			}
+		}
+	}
+	row := make([]string, len(t.indexes))
+	for i, index := range t.indexes {
+		if index < len(record) {
+			row[i] = record[index]
+		}
+	}
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+// Flush is a no-op, the table is written by Render once all rows are known
+func (t *tableWriter) Flush() {}
+
+// Render writes the buffered rows aligned to fit the terminal width
+func (t *tableWriter) Render() {
+	if len(t.rows) == 0 {
+		return
+	}
+	widths := make([]int, len(t.rows[0]))
+	for _, row := range t.rows {
+		for i, value := range row {
+			if n := len([]rune(value)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	fitWidths(widths, terminalWidth())
+
+	statusIndex := -1
+	for i, name := range t.rows[0] {
+		if name == "status" {
+			statusIndex = i
+		}
+	}
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cell := fmt.Sprintf("%-*s", widths[i], truncate(value, widths[i]))
+			if t.color && i == statusIndex {
+				cell = colorize(cell, value)
+			}
+			cells[i] = cell
+		}
+		fmt.Fprintln(t.out, strings.TrimRight(strings.Join(cells, " "), " "))
+	}
+}
+
+// fitWidths shrinks the widest columns until the table (with separators) fits within max
+func fitWidths(widths []int, max int) {
+	total := len(widths) - 1
+	for _, w := range widths {
+		total += w
+	}
+	for total > max {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// truncate shortens value to width runes, marking truncation with an ellipsis
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// colorize colors the cell based on the status value
+func colorize(cell, status string) string {
+	switch status {
+	case StatusOK:
+		return colorGreen + cell + colorReset
+	case StatusWarning:
+		return colorYellow + cell + colorReset
+	case StatusBreached:
+		return colorRed + cell + colorReset
+	}
+	return cell
+}
+
+// terminalWidth returns the COLUMNS environment variable or the terminal width, defaulting to 120
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if width := ttyWidth(os.Stdout); width > 0 {
+		return width
+	}
+	return 120
+}
+
+// isTerminal returns true when f is a character device e.g an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"log"
+	"os"
+	"strings"
+)
+
+// runTag adds tags to, or removes tags from, every slo matching the tag query, writing the changed slos to stdout as csv
+func runTag(ctx context.Context, args []string) {
+	if len(args) == 0 || (args[0] != "add" && args[0] != "remove") {
+		fatalf("Usage: tag add|remove -tag key:value [-dry-run]")
+	}
+	action := args[0]
+	fs := flag.NewFlagSet("tag "+action, flag.ContinueOnError)
+	var tags stringList
+	fs.Var(&tags, "tag", "comma separated tags to "+action+" e.g team:newname")
+	dryRun := fs.Bool("dry-run", false, "only write the tag changes, without updating the slos")
+	parseFlags(fs, args[1:])
+	if !*dryRun {
+		requireWritable("tag " + action)
+	}
+	if len(tags) == 0 {
+		fatalf("No -tag to %s", action)
+	}
+
+	slos, err := getSLODefinitions(ctx, options.limit, options.tagQuery)
+	if err != nil {
+		fatalf("Error when calling `ServiceLevelObjectivesApi.ListSLOs`: %v\n", err)
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+	cols := []string{"name", "slo_id", "tags_before", "tags_after"}
+	if err := writer.Write(cols); err != nil {
+		fatalf("Unable to write to stdout, err: %s", err)
+	}
+
+	apiClient := newAPIClient()
+	changed := 0
+	for counter, slo := range slos {
+		before := slo.GetTags()
+		after := editTags(before, action, tags)
+		if len(after) == len(before) {
+			continue
+		}
+		data := []string{slo.GetName(), slo.GetId(), strings.Join(before, ","), strings.Join(after, ",")}
+		if err := writer.Write(data); err != nil {
+			fatalf("Unable to write to stdout, err: %s", err)
+		}
+		changed++
+		if *dryRun {
+			continue
+		}
+		log.Printf("(%d of %d) Updating tags s: %s", counter+1, len(slos), slo.GetId())
+		slo.SetTags(after)
+		if _, _, err := apiClient.ServiceLevelObjectivesApi.UpdateSLO(ctx, slo.GetId(), sloDefinition(slo)); err != nil {
+			fatalf("Error when calling `ServiceLevelObjectivesApi.UpdateSLO` s: %s, err: %v\n", slo.GetId(), err)
+		}
+		if err := sleepContext(ctx, options.sleep); err != nil {
+			writer.Flush()
+			fatalf("Tagging stopped, tags of %d SLOs changed, err: %s", changed, err)
+		}
+	}
+
+	if *dryRun {
+		log.Printf("Dry run - tags of %d of %d SLOs would change", changed, len(slos))
+		return
+	}
+	log.Printf("Done - tags of %d of %d SLOs changed", changed, len(slos))
+}
+
+// editTags returns the tags with the edit tags added (when missing) or removed
+func editTags(tags []string, action string, edit stringList) []string {
+	edited := make([]string, 0, len(tags)+len(edit))
+	for _, tag := range tags {
+		if action == "remove" && edit.contains(tag) {
+			continue
+		}
+		edited = append(edited, tag)
+	}
+	if action == "add" {
+		for _, tag := range edit {
+			if !stringList(edited).contains(tag) {
+				edited = append(edited, tag)
+			}
+		}
+	}
+	return edited
+}
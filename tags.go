@@ -0,0 +1,22 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// sloTagValue returns the value of the first key:value tag of the slo with the key, empty if there is none
+func sloTagValue(slo datadog.ServiceLevelObjective, key string) string {
+	for _, tag := range slo.GetTags() {
+		if strings.HasPrefix(tag, key+":") {
+			return strings.TrimPrefix(tag, key+":")
+		}
+	}
+	return ""
+}
+
+// tagColumn returns the report column of a -tag-columns tag key e.g tag_env for env
+func tagColumn(key string) string {
+	return "tag_" + key
+}
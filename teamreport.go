@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// teamReportListed is the number of non OK rows listed in a team's notification
+const teamReportListed = 20
+
+// unassignedTeam is the team of slos with no team: tag or hierarchy team
+const unassignedTeam = "unassigned"
+
+// teamNotification is where a team's own rows are sent e.g {"slack_webhook": "https://hooks.slack.com/...", "emails": ["a@example.com"]}
+type teamNotification struct {
+	SlackWebhook string   `json:"slack_webhook"`
+	Emails       []string `json:"emails"`
+}
+
+// reportTeam returns the team of the slo: its hierarchy team when a hierarchy is configured, otherwise its team: tag
+func reportTeam(slo datadog.ServiceLevelObjective) string {
+	team := sloTagValue(slo, "team")
+	if config.Hierarchy != nil {
+		team = sloHierarchy(slo)[1]
+	}
+	if team == "" {
+		return unassignedTeam
+	}
+	return team
+}
+
+// teamWriter splits the records by the team of their slo before writing them to the next writer
+type teamWriter struct {
+	next   reportWriter
+	header []string
+	// teams maps slo ids to their team, set as slos are reported
+	teams map[string]string
+	rows  map[string][][]string
+}
+
+// newTeamWriter returns a team writer writing records to next
+func newTeamWriter(next reportWriter) *teamWriter {
+	return &teamWriter{next: next, teams: map[string]string{}, rows: map[string][][]string{}}
+}
+
+// addSLO records the team of the slo, before its rows are written
+func (w *teamWriter) addSLO(slo datadog.ServiceLevelObjective) {
+	w.teams[slo.GetId()] = reportTeam(slo)
+}
+
+// Write keeps the record under the team of its slo, composite rows have no team and are left out
+func (w *teamWriter) Write(record []string) error {
+	if w.header == nil {
+		w.header = record
+		return w.next.Write(record)
+	}
+	id, _ := recordLookup(w.header, record)("slo_id")
+	if team, found := w.teams[id]; found {
+		w.rows[team] = append(w.rows[team], record)
+	}
+	return w.next.Write(record)
+}
+
+// Flush flushes the next writer
+func (w *teamWriter) Flush() {
+	w.next.Flush()
+}
+
+// teamNames returns the teams with rows, sorted
+func (w *teamWriter) teamNames() []string {
+	names := make([]string, 0, len(w.rows))
+	for team := range w.rows {
+		names = append(names, team)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unsafeFileChars are replaced in team names used in file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// teamReportPath returns the report file of the team next to the report e.g /tmp/slo_report.team_payments.csv
+func teamReportPath(reportPath, team string) string {
+	plain := strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ".team_" + unsafeFileChars.ReplaceAllString(team, "_") + ".csv"
+	if options.encryptWith != "" {
+		return encryptedPath(plain, options.encryptWith)
+	}
+	return plain
+}
+
+// teamCSV returns the header and rows of the team as csv
+func (w *teamWriter) teamCSV(team string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.writeTeamCSV(&buf, team); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTeamCSV writes the header and rows of the team as csv to out
+func (w *teamWriter) writeTeamCSV(out io.Writer, team string) error {
+	writer, err := newCSVWriter(out)
+	if err != nil {
+		return err
+	}
+	for _, record := range append([][]string{w.header}, w.rows[team]...) {
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTeamReports writes a csv file per team next to the report, returning their paths
+func (w *teamWriter) writeTeamReports(reportPath string) ([]string, error) {
+	var paths []string
+	for _, team := range w.teamNames() {
+		path := teamReportPath(reportPath, team)
+		file, err := createReportFile(path)
+		if err != nil {
+			return paths, err
+		}
+		if err := w.writeTeamCSV(file, team); err != nil {
+			file.Close()
+			return paths, fmt.Errorf("%s: %s", path, err)
+		}
+		if err := file.Close(); err != nil {
+			return paths, fmt.Errorf("%s: %s", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// teamMessage returns the summary of the team's rows: the rows by status followed by the non OK rows
+func (w *teamWriter) teamMessage(team string) string {
+	statuses := map[string]int{}
+	var listed []string
+	for _, record := range w.rows[team] {
+		lookup := recordLookup(w.header, record)
+		status, _ := lookup("status")
+		if errStr, _ := lookup("error"); errStr != "" && status == "" {
+			status = "ERROR"
+		}
+		statuses[status]++
+		if status == StatusOK {
+			continue
+		}
+		name, _ := lookup("name")
+		timeframe, _ := lookup("timeframe")
+		group, _ := lookup("group")
+		sli, _ := lookup("overall_status")
+		target, _ := lookup("target")
+		if group != "" {
+			name += " (" + group + ")"
+		}
+		if errStr, _ := lookup("error"); errStr != "" {
+			listed = append(listed, fmt.Sprintf("- %s %s: %s, %s", name, timeframe, status, errStr))
+			continue
+		}
+		listed = append(listed, fmt.Sprintf("- %s %s: %s, sli %s, target %s", name, timeframe, status, sli, target))
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "SLO report for team %s, %s: %d rows", team, startedAt.UTC().Format("2006-01-02"), len(w.rows[team]))
+	for _, status := range []string{StatusOK, StatusWarning, StatusBreached, StatusNoData, StatusDeleted, "ERROR"} {
+		if statuses[status] > 0 {
+			fmt.Fprintf(&text, ", %d %s", statuses[status], status)
+		}
+	}
+	text.WriteString("\n")
+	if len(listed) > teamReportListed {
+		listed = append(listed[:teamReportListed], fmt.Sprintf("- and %d more", len(listed)-teamReportListed))
+	}
+	for _, line := range listed {
+		text.WriteString(line + "\n")
+	}
+	return text.String()
+}
+
+// notifyTeams sends each team configured in team_notifications its own rows, teams with rows and no
+// notification configured are logged
+func (w *teamWriter) notifyTeams() {
+	for _, team := range w.teamNames() {
+		dest, found := config.TeamNotifications[team]
+		if !found {
+			log.Printf("No team notification configured for team: %s, %d rows not sent", team, len(w.rows[team]))
+			continue
+		}
+		text := w.teamMessage(team)
+		if dest.SlackWebhook != "" {
+			if err := postSlackMessage(dest.SlackWebhook, text); err != nil {
+				log.Printf("Unable to notify team: %s on slack, err: %s", team, err)
+			} else {
+				log.Printf("Team %s notified on slack", team)
+			}
+		}
+		if len(dest.Emails) > 0 {
+			if err := w.emailTeam(team, dest.Emails, text); err != nil {
+				log.Printf("Unable to email team: %s, err: %s", team, err)
+			} else {
+				log.Printf("Team %s emailed: %s", team, strings.Join(dest.Emails, ", "))
+			}
+		}
+	}
+}
+
+// postSlackMessage posts the text to a slack incoming webhook
+func postSlackMessage(webhook, text string) error {
+	content, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	resp, err := ddHTTPClient.Post(webhook, "application/json", bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+// emailTeam emails the text to the team with its rows attached as csv, through -smtp-addr authenticated with
+// SMTP_USERNAME and SMTP_PASSWORD when set. The rows are not attached to encrypted reports.
+func (w *teamWriter) emailTeam(team string, to []string, text string) error {
+	if options.smtpAddr == "" {
+		return fmt.Errorf("-smtp-addr is not set")
+	}
+	var msg bytes.Buffer
+	body := multipart.NewWriter(&msg)
+	fmt.Fprintf(&msg, "From: %s\r\nTo: %s\r\nSubject: SLO report for team %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n",
+		options.smtpFrom, strings.Join(to, ", "), team, body.Boundary())
+	part, err := body.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	if options.encryptWith == "" {
+		content, err := w.teamCSV(team)
+		if err != nil {
+			return err
+		}
+		part, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"text/csv; charset=utf-8"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", teamReportPath("slo_report.csv", team))},
+		})
+		if err != nil {
+			return err
+		}
+		encoded := base64.StdEncoding.EncodeToString(content)
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded + "\r\n"))
+	}
+	if err := body.Close(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		host, _, err := net.SplitHostPort(options.smtpAddr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+	}
+	return smtp.SendMail(options.smtpAddr, auth, options.smtpFrom, to, msg.Bytes())
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// ddTeam is a team from the datadog teams api
+type ddTeam struct {
+	ID         string `json:"id"`
+	Attributes struct {
+		Name   string `json:"name"`
+		Handle string `json:"handle"`
+	} `json:"attributes"`
+}
+
+// teams are the datadog teams keyed by lower case handle and name, loaded with -resolve-teams
+var teams map[string]ddTeam
+
+// loadTeams returns all datadog teams keyed by lower case handle and name
+func loadTeams(ctx context.Context) (map[string]ddTeam, error) {
+	all := map[string]ddTeam{}
+	pageSize := 100
+	for page := 0; ; page++ {
+		var resp struct {
+			Data []ddTeam `json:"data"`
+		}
+		query := url.Values{
+			"page[size]":   {fmt.Sprintf("%d", pageSize)},
+			"page[number]": {fmt.Sprintf("%d", page)},
+		}
+		if err := datadogGet(ctx, "/api/v2/team", query, &resp); err != nil {
+			return nil, err
+		}
+		for _, team := range resp.Data {
+			all[strings.ToLower(team.Attributes.Handle)] = team
+			all[strings.ToLower(team.Attributes.Name)] = team
+		}
+		if len(resp.Data) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// sloTeam returns the team matching the slo's team tag, false if the slo has no team tag or it matches no team
+func sloTeam(slo datadog.ServiceLevelObjective) (string, ddTeam, bool) {
+	tag := sloTagValue(slo, "team")
+	team, found := teams[strings.ToLower(tag)]
+	return tag, team, tag != "" && found
+}
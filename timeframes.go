@@ -0,0 +1,26 @@
+package main
+
+import "github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+
+// recoverThresholds sets the fields of thresholds the api client left unparsed because their timeframe is newer
+// than the client's (e.g 1d), so every timeframe the api returns can be reported
+func recoverThresholds(slos []datadog.ServiceLevelObjective) {
+	for i := range slos {
+		for j, threshold := range slos[i].Thresholds {
+			raw := threshold.UnparsedObject
+			if raw == nil {
+				continue
+			}
+			timeframe, _ := raw["timeframe"].(string)
+			target, ok := raw["target"].(float64)
+			if timeframe == "" || !ok {
+				continue
+			}
+			recovered := datadog.SLOThreshold{Timeframe: datadog.SLOTimeframe(timeframe), Target: target}
+			if warning, ok := raw["warning"].(float64); ok {
+				recovered.Warning = &warning
+			}
+			slos[i].Thresholds[j] = recovered
+		}
+	}
+}
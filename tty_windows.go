@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+	procGetConsoleMode             = kernel32.NewProc("GetConsoleMode")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+)
+
+// enableVirtualTerminalProcessing is the console mode interpreting ansi escape sequences
+const enableVirtualTerminalProcessing = 0x0004
+
+// consoleScreenBufferInfo is the windows CONSOLE_SCREEN_BUFFER_INFO struct
+type consoleScreenBufferInfo struct {
+	size, cursorPosition     [2]int16
+	attributes               uint16
+	left, top, right, bottom int16
+	maximumWindowSize        [2]int16
+}
+
+// ttyWidth returns the width of the console window f is attached to, or 0 if unknown
+func ttyWidth(f *os.File) int {
+	var info consoleScreenBufferInfo
+	if ret, _, _ := procGetConsoleScreenBufferInfo.Call(f.Fd(), uintptr(unsafe.Pointer(&info))); ret == 0 {
+		return 0
+	}
+	return int(info.right-info.left) + 1
+}
+
+// enableANSI turns on ansi escape sequences in the console f is attached to, returning false when the console
+// doesn't support them e.g before windows 10
+func enableANSI(f *os.File) bool {
+	var mode uint32
+	if ret, _, _ := procGetConsoleMode.Call(f.Fd(), uintptr(unsafe.Pointer(&mode))); ret == 0 {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	ret, _, _ := procSetConsoleMode.Call(f.Fd(), uintptr(mode|enableVirtualTerminalProcessing))
+	return ret != 0
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"sort"
+
+	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+)
+
+// defaultUnstableOperations are the unstable api client operations used by this script, enabled unless the config
+// unstable_operations disables them
+var defaultUnstableOperations = map[string]bool{"GetSLOHistory": true}
+
+// unstableOperations returns the default unstable operations with the config unstable_operations applied, leaving
+// out operations the api client version in use does not have as unstable e.g once they are stable
+func unstableOperations() map[string]bool {
+	configuration := datadog.NewConfiguration()
+	operations := map[string]bool{}
+	for _, ops := range []map[string]bool{defaultUnstableOperations, config.UnstableOperations} {
+		for operation, enabled := range ops {
+			if configuration.IsUnstableOperation(operation) {
+				operations[operation] = enabled
+			}
+		}
+	}
+	return operations
+}
+
+// checkUnstableOperations logs the config unstable_operations the api client version in use does not have as unstable,
+// they are ignored so upgrading the client does not fail runs
+func checkUnstableOperations(operations map[string]bool) {
+	configuration := datadog.NewConfiguration()
+	names := make([]string, 0, len(operations))
+	for operation := range operations {
+		names = append(names, operation)
+	}
+	sort.Strings(names)
+	for _, operation := range names {
+		if !configuration.IsUnstableOperation(operation) {
+			log.Printf("unstable_operations: %s is not an unstable operation of the api client, ignored", operation)
+		}
+	}
+}
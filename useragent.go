@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// userAgent returns the User-Agent of the tool's api calls e.g slo-report/v1.2.3 (linux/amd64; go1.15; run nightly),
+// so its traffic can be told apart from other api clients in datadog usage attribution and egress logs
+func userAgent() string {
+	product := options.userAgent
+	if product == "" {
+		product = "slo-report/" + version
+	}
+	comment := []string{runtime.GOOS + "/" + runtime.GOARCH, runtime.Version()}
+	if options.runLabel != "" {
+		comment = append(comment, "run "+options.runLabel)
+	}
+	return fmt.Sprintf("%s (%s)", product, strings.Join(comment, "; "))
+}
+
+// userAgentTransport sets the tool's User-Agent on requests before sending them with the default transport
+type userAgentTransport struct{}
+
+// RoundTrip sends a copy of the request with the User-Agent set
+func (userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgent())
+	return http.DefaultTransport.RoundTrip(req)
+}
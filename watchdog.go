@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"log"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+// stallGrace is how long a run stopped by the watchdog has to exit before it is killed, for calls that ignore the
+// cancellation
+const stallGrace = time.Minute
+
+// runProgress is what changes while the run makes progress
+type runProgress struct {
+	processed, listed int64
+	calls, retries    int
+}
+
+// currentProgress returns the progress of the run so far
+func currentProgress() runProgress {
+	s := summary.snapshot()
+	return runProgress{
+		processed: atomic.LoadInt64(&processedSLOs),
+		listed:    atomic.LoadInt64(&listedSLOs),
+		calls:     s.HistoryCalls,
+		retries:   s.Retries,
+	}
+}
+
+// watchStalls stops the run when it makes no progress for timeout e.g a hung api call, logging the progress and the
+// goroutine stacks of where it is stuck, and kills it if it hasn't exited stallGrace later, so scheduled runs don't
+// hang until the next one
+func watchStalls(ctx context.Context, cancel context.CancelFunc, timeout time.Duration) {
+	go func() {
+		last, changedAt := currentProgress(), runClock.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-runClock.After(timeout / 4):
+			}
+			if progress := currentProgress(); progress != last {
+				last, changedAt = progress, runClock.Now()
+				continue
+			}
+			if since(changedAt) < timeout {
+				continue
+			}
+			log.Printf("No progress for %s (-stall-timeout), stopping the run, goroutines:", timeout)
+			dumpProgress()
+			pprof.Lookup("goroutine").WriteTo(log.Writer(), 1)
+			cancel()
+			<-runClock.After(stallGrace)
+			fatalf("Run still stalled %s after being stopped, exiting", stallGrace)
+		}
+	}()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// xlsxParts are the fixed parts of a single sheet workbook, the sheet itself is written from the records
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="SLO report" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// openXLSXOutput creates an excel workbook report file, encrypted if enabled, with numbers written as number cells,
+// columns are renamed but numbers are not locale formatted, excel formats them
+func openXLSXOutput(path string) (*output, error) {
+	file, err := createReportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := &xlsxWriter{}
+	return &output{format: "xlsx", path: path, writer: writer, close: func() error {
+		if err := writer.writeWorkbook(file); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}}, nil
+}
+
+// xlsxWriter keeps the records for the workbook written once the report is complete
+type xlsxWriter struct {
+	numeric []bool
+	sheet   bytes.Buffer
+	rows    int
+}
+
+// Write adds the record as a sheet row, the header decides which columns are numeric
+func (w *xlsxWriter) Write(record []string) error {
+	if w.numeric == nil {
+		derived := map[string]bool{}
+		for _, col := range config.DerivedColumns {
+			derived[col.Name] = true
+		}
+		w.numeric = make([]bool, len(record))
+		for i, col := range record {
+			w.numeric[i] = stringList(numericColumns).contains(col) || derived[col]
+		}
+		if len(config.ColumnNames) > 0 {
+			record = renameColumns(record)
+		}
+		w.writeRow(record, false)
+		return nil
+	}
+	w.writeRow(record, true)
+	return nil
+}
+
+// writeRow appends the record to the sheet data, numeric column values parsing as numbers are number cells
+func (w *xlsxWriter) writeRow(record []string, numbers bool) {
+	w.rows++
+	fmt.Fprintf(&w.sheet, `<row r="%d">`, w.rows)
+	for i, value := range record {
+		if numbers && i < len(w.numeric) && w.numeric[i] {
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				fmt.Fprintf(&w.sheet, `<c t="n"><v>%s</v></c>`, value)
+				continue
+			}
+		}
+		w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(&w.sheet, []byte(value))
+		w.sheet.WriteString(`</t></is></c>`)
+	}
+	w.sheet.WriteString(`</row>`)
+}
+
+// Flush is a no-op, the workbook is written once the report is complete
+func (w *xlsxWriter) Flush() {}
+
+// writeWorkbook writes the workbook zip archive with the sheet of the records
+func (w *xlsxWriter) writeWorkbook(out io.Writer) error {
+	archive := zip.NewWriter(out)
+	for _, part := range xlsxParts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	f, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+	if _, err := w.sheet.WriteTo(f); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return archive.Close()
+}